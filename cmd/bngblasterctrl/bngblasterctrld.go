@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/rtbrick/bngblaster-controller/pkg/controller"
 	"github.com/rtbrick/bngblaster-controller/pkg/daemonize"
+	"github.com/rtbrick/bngblaster-controller/pkg/otlp"
 	"github.com/rtbrick/bngblaster-controller/pkg/server"
 )
 
@@ -21,7 +23,16 @@ func main() {
 	addr := flag.String("addr", ":8001", "HTTP network address")
 	directory := flag.String("d", controller.DefaultConfigFolder, "config folder")
 	executable := flag.String("e", controller.DefaultExecutable, "bngblaster executable")
+	configureInterfaces := flag.String("configure-interfaces", "", "interfaces which could be configured via the API (comma separated, patterns like ens1f* allowed)")
+	library := flag.String("library", "", "shared file library folder (default <config folder>/.library)")
 	upload := flag.Bool("upload", false, "allow file upload")
+	uploadQuota := flag.Int64("upload-quota", 0, "maximum size of the uploaded files per instance in MB (0 is unlimited)")
+	uploadFiles := flag.Int("upload-files", 0, "maximum number of uploaded files per instance (0 is unlimited)")
+	uploadMaxSize := flag.Int64("upload-max-size", server.DefaultMaxUploadSize>>20, "maximum size of an uploaded file in MB (0 is unlimited)")
+	metricsInterval := flag.Duration("metrics-interval", 0, "collect instance metrics in the background with this interval (0 collects on scrape)")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "push metrics to this OTLP/HTTP collector URL (e.g. http://localhost:4318)")
+	otlpInterval := flag.Duration("otlp-interval", otlp.DefaultInterval, "OTLP metrics push interval")
+	otlpHeaders := flag.String("otlp-headers", "", "additional OTLP HTTP headers (key=value,...)")
 
 	// logging
 	debug := flag.Bool("debug", false, "turn on debug logging")
@@ -33,13 +44,59 @@ func main() {
 	// setup logging
 	initializeLogger(*debug, *console, *color)
 
+	metrics := controller.NewMetrics()
 	repo := controller.NewDefaultRepository(
 		controller.WithConfigFolder(*directory),
 		controller.WithExecutable(*executable),
-		controller.WithUpload(*upload))
-	srv := server.NewServer(repo)
+		controller.WithLibraryFolder(*library),
+		controller.WithUpload(*upload),
+		controller.WithMetrics(metrics))
+	srv := server.NewServer(repo,
+		server.WithMetricsInterval(*metricsInterval),
+		server.WithMetrics(metrics),
+		server.WithFileQuota(*uploadQuota<<20, *uploadFiles),
+		server.WithMaxUploadSize(*uploadMaxSize<<20),
+		server.WithConfigurableInterfaces(parseList(*configureInterfaces)...))
 	srv.Version = Version
+	var exporter *otlp.Exporter
+	if *otlpEndpoint != "" {
+		var err error
+		exporter, err = otlp.NewExporter(srv.Gatherer(), *otlpEndpoint,
+			otlp.WithInterval(*otlpInterval),
+			otlp.WithHeaders(parseHeaders(*otlpHeaders)))
+		if err != nil {
+			log.Fatal().Err(err).Send()
+		}
+		exporter.Start()
+	}
 	serve(*addr, srv)
+	if exporter != nil {
+		exporter.Stop()
+	}
+	srv.Close()
+}
+
+// parseList parses a comma separated list.
+func parseList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// parseHeaders parses a comma separated list of key=value pairs.
+func parseHeaders(headers string) map[string]string {
+	result := map[string]string{}
+	for _, header := range strings.Split(headers, ",") {
+		k, v, ok := strings.Cut(header, "=")
+		if ok && strings.TrimSpace(k) != "" {
+			result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return result
 }
 
 func serve(addr string, handler http.Handler) {
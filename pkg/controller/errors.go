@@ -2,6 +2,11 @@
 // Copyright (C) 2020-2025, RtBrick, Inc.
 package controller
 
+import (
+	"fmt"
+	"strings"
+)
+
 // BlasterControllerError represents an blaster error.
 type BlasterControllerError struct {
 	ErrorString string
@@ -11,10 +16,36 @@ type BlasterControllerError struct {
 func (pe *BlasterControllerError) Error() string { return pe.ErrorString }
 
 var (
+	// ErrInvalidName the instance name is empty.
+	ErrInvalidName = &BlasterControllerError{"invalid instance name"}
 	// ErrBlasterNotExists there is not blaster instance.
 	ErrBlasterNotExists = &BlasterControllerError{"blaster instance does not exist"}
 	// ErrBlasterRunning there is one BlasterInstance running.
 	ErrBlasterRunning = &BlasterControllerError{"blaster instance is running"}
 	// ErrBlasterNotRunning there is no BlasterInstance running.
 	ErrBlasterNotRunning = &BlasterControllerError{"blaster instance is not running"}
+	// ErrLibraryFileNotExists a referenced library file does not exist.
+	ErrLibraryFileNotExists = &BlasterControllerError{"library file does not exist"}
+	// ErrInvalidStreamConfig the stream config does not exist or is outside of the allowed folders.
+	ErrInvalidStreamConfig = &BlasterControllerError{"invalid stream config"}
+	// ErrLeased the instance or interface is leased by someone else.
+	ErrLeased = &BlasterControllerError{"locked by lease"}
+	// ErrLeaseNotExists the lease does not exist or is expired.
+	ErrLeaseNotExists = &BlasterControllerError{"lease does not exist"}
+	// ErrInvalidLease the lease request is invalid.
+	ErrInvalidLease = &BlasterControllerError{"invalid lease"}
 )
+
+// InterfaceConflictError is returned if interfaces of the configuration
+// are used by a running instance.
+type InterfaceConflictError struct {
+	// Instance is the running instance
+	Instance string
+	// Interfaces are the interfaces used by both instances
+	Interfaces []string
+}
+
+// Error implements error interface.
+func (e *InterfaceConflictError) Error() string {
+	return fmt.Sprintf("interface %s used by running instance %s", strings.Join(e.Interfaces, ", "), e.Instance)
+}
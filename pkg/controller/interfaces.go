@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"sort"
+)
+
+// ConfigInterfaces returns the sorted host interfaces used by a bngblaster
+// configuration, which are the interfaces of the sections links, access,
+// network and a10nsp. Each section could be a list or a single object.
+// The virtual interfaces of the lag section are ignored.
+func ConfigInterfaces(config []byte) ([]string, error) {
+	var c struct {
+		Interfaces map[string]json.RawMessage `json:"interfaces"`
+	}
+	if err := json.Unmarshal(config, &c); err != nil {
+		return nil, err
+	}
+	type section struct {
+		Interface string `json:"interface"`
+	}
+	unique := map[string]bool{}
+	for name, raw := range c.Interfaces {
+		if name == "lag" {
+			continue
+		}
+		var sections []section
+		if err := json.Unmarshal(raw, &sections); err != nil {
+			var s section
+			if err := json.Unmarshal(raw, &s); err != nil {
+				// Options like io-mode or tx-interval.
+				continue
+			}
+			sections = []section{s}
+		}
+		for _, s := range sections {
+			if s.Interface != "" {
+				unique[s.Interface] = true
+			}
+		}
+	}
+	interfaces := make([]string, 0, len(unique))
+	for name := range unique {
+		interfaces = append(interfaces, name)
+	}
+	sort.Strings(interfaces)
+	return interfaces, nil
+}
+
+// interfaceConflict returns an InterfaceConflictError if a running instance
+// uses an interface of the configuration of the instance name.
+func (r *DefaultRepository) interfaceConflict(name string) error {
+	config, err := r.config(name)
+	if err != nil {
+		return nil
+	}
+	interfaces, err := ConfigInterfaces(config)
+	if err != nil || len(interfaces) == 0 {
+		// The bngblaster reports the missing or invalid configuration.
+		return nil
+	}
+	for _, instance := range r.Instances() {
+		if instance == name || !r.Running(instance) {
+			continue
+		}
+		config, err := os.ReadFile(path.Join(r.configFolder, instance, ConfigFilename))
+		if err != nil {
+			continue
+		}
+		used, err := ConfigInterfaces(config)
+		if err != nil {
+			continue
+		}
+		var conflicts []string
+		for _, a := range interfaces {
+			for _, b := range used {
+				if a == b {
+					conflicts = append(conflicts, a)
+				}
+			}
+		}
+		if len(conflicts) > 0 {
+			return &InterfaceConflictError{Instance: instance, Interfaces: conflicts}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigInterfaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", config: `{}`, want: []string{}},
+		{
+			name: "sections",
+			config: `{"interfaces": {
+				"io-mode": "packet_mmap_raw",
+				"links": [{"interface": "eth1", "lag-interface": "lag0"}, {"interface": "eth2", "lag-interface": "lag0"}],
+				"lag": [{"interface": "lag0"}],
+				"access": [{"interface": "lag0", "outer-vlan-min": 1}, {"interface": "eth3"}],
+				"network": {"interface": "eth4", "address": "10.0.0.1/24"},
+				"a10nsp": [{"interface": "eth4"}]
+			}}`,
+			want: []string{"eth1", "eth2", "eth3", "eth4", "lag0"},
+		},
+		{name: "invalid", config: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConfigInterfaces([]byte(tt.config))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDefaultRepository_interfaceConflict(t *testing.T) {
+	configFolder := t.TempDir()
+	r := NewDefaultRepository(WithConfigFolder(configFolder))
+	require.NoError(t, r.Create("running", []byte(`{"interfaces": {"network": {"interface": "eth1"}, "access": [{"interface": "eth2"}]}}`)))
+	require.NoError(t, r.Create("conflict", []byte(`{"interfaces": {"access": [{"interface": "eth2"}, {"interface": "eth3"}]}}`)))
+	require.NoError(t, r.Create("free", []byte(`{"interfaces": {"access": [{"interface": "eth3"}]}}`)))
+	require.NoError(t, r.Create("invalid", []byte(`{`)))
+	require.NoError(t, os.WriteFile(path.Join(configFolder, "running", runPidFilename), []byte(strconv.Itoa(os.Getpid())), permission))
+
+	for _, name := range []string{"free", "invalid"} {
+		require.NoError(t, r.Check(name, RunningConfig{}))
+	}
+	require.Equal(t, ErrBlasterRunning, r.Check("running", RunningConfig{}))
+
+	want := &InterfaceConflictError{Instance: "running", Interfaces: []string{"eth2"}}
+	err := r.Check("conflict", RunningConfig{})
+	var conflict *InterfaceConflictError
+	require.True(t, errors.As(err, &conflict))
+	require.Equal(t, want, conflict)
+	require.Equal(t, "interface eth2 used by running instance running", err.Error())
+	require.Equal(t, want, r.Start("conflict", RunningConfig{}))
+}
@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLeaseTTL is the time to live of a lease without explicit TTL.
+	DefaultLeaseTTL = 5 * time.Minute
+	// MaxLeaseTTL is the maximum time to live of a lease, longer
+	// leases must be renewed with heartbeats.
+	MaxLeaseTTL = 24 * time.Hour
+)
+
+// Lease grants exclusive access to an instance and/or host interfaces
+// until it expires or is released.
+type Lease struct {
+	// ID of the lease, which is the token of the holder. It is only
+	// returned to the holder.
+	ID string `json:"id,omitempty"`
+	// Holder is a description of the holder, e.g. the CI pipeline
+	Holder string `json:"holder,omitempty"`
+	// Instance is the leased instance
+	Instance string `json:"instance,omitempty"`
+	// Interfaces are the leased host interfaces
+	Interfaces []string `json:"interfaces,omitempty"`
+	// TTL is the time to live in seconds, every renewal extends the lease by the TTL
+	TTL int `json:"ttl"`
+	// Expires is the time the lease expires without renewal
+	Expires time.Time `json:"expires"`
+}
+
+// Leases manages the leases in memory, they do not survive a restart of the controller.
+type Leases struct {
+	mu     sync.Mutex
+	leases map[string]*Lease
+	// now returns the current time and is replaced in tests.
+	now func() time.Time
+}
+
+// NewLeases is a constructor function for Leases.
+func NewLeases() *Leases {
+	return &Leases{
+		leases: map[string]*Lease{},
+		now:    time.Now,
+	}
+}
+
+// expire removes the expired leases, the caller must hold the lock.
+func (l *Leases) expire() {
+	now := l.now()
+	for id, lease := range l.leases {
+		if !now.Before(lease.Expires) {
+			delete(l.leases, id)
+		}
+	}
+}
+
+// conflict returns an error wrapping ErrLeased if the instance or one of the
+// interfaces is leased by another lease than id, the caller must hold the lock.
+func (l *Leases) conflict(id string, instance string, interfaces []string) error {
+	for _, lease := range l.sorted() {
+		if lease.ID == id {
+			continue
+		}
+		if instance != "" && lease.Instance == instance {
+			return fmt.Errorf("%w: instance %s is leased by %q until %s",
+				ErrLeased, instance, lease.Holder, lease.Expires.UTC().Format(time.RFC3339))
+		}
+		for _, leased := range lease.Interfaces {
+			for _, name := range interfaces {
+				if name == leased {
+					return fmt.Errorf("%w: interface %s is leased by %q until %s",
+						ErrLeased, name, lease.Holder, lease.Expires.UTC().Format(time.RFC3339))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sorted returns the leases sorted by expiry, the caller must hold the lock.
+func (l *Leases) sorted() []*Lease {
+	leases := make([]*Lease, 0, len(l.leases))
+	for _, lease := range l.leases {
+		leases = append(leases, lease)
+	}
+	sort.Slice(leases, func(i, j int) bool { return leases[i].Expires.Before(leases[j].Expires) })
+	return leases
+}
+
+// Acquire grants a new lease for the instance and the interfaces of the request
+// and returns it with its ID. It returns an error wrapping ErrLeased if the
+// instance or an interface is already leased.
+func (l *Leases) Acquire(request Lease) (Lease, error) {
+	if request.Instance == "" && len(request.Interfaces) == 0 {
+		return Lease{}, fmt.Errorf("%w: neither instance nor interfaces", ErrInvalidLease)
+	}
+	ttl := DefaultLeaseTTL
+	if request.TTL != 0 {
+		ttl = time.Duration(request.TTL) * time.Second
+	}
+	if ttl <= 0 || ttl > MaxLeaseTTL {
+		return Lease{}, fmt.Errorf("%w: ttl must be between 1 and %d seconds", ErrInvalidLease, int(MaxLeaseTTL.Seconds()))
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return Lease{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+	if err := l.conflict("", request.Instance, request.Interfaces); err != nil {
+		return Lease{}, err
+	}
+	lease := &Lease{
+		ID:         hex.EncodeToString(b),
+		Holder:     request.Holder,
+		Instance:   request.Instance,
+		Interfaces: append([]string(nil), request.Interfaces...),
+		TTL:        int(ttl.Seconds()),
+		Expires:    l.now().Add(ttl),
+	}
+	l.leases[lease.ID] = lease
+	return *lease, nil
+}
+
+// Renew extends the lease by its TTL.
+func (l *Leases) Renew(id string) (Lease, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+	lease, ok := l.leases[id]
+	if !ok {
+		return Lease{}, ErrLeaseNotExists
+	}
+	lease.Expires = l.now().Add(time.Duration(lease.TTL) * time.Second)
+	return *lease, nil
+}
+
+// Release removes the lease.
+func (l *Leases) Release(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+	if _, ok := l.leases[id]; !ok {
+		return ErrLeaseNotExists
+	}
+	delete(l.leases, id)
+	return nil
+}
+
+// List returns the active leases sorted by expiry without their IDs.
+func (l *Leases) List() []Lease {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+	leases := make([]Lease, 0, len(l.leases))
+	for _, lease := range l.sorted() {
+		public := *lease
+		public.ID = ""
+		leases = append(leases, public)
+	}
+	return leases
+}
+
+// Check returns an error wrapping ErrLeased if the instance or one of the
+// interfaces is leased by another lease than id (empty for requests without lease).
+func (l *Leases) Check(id string, instance string, interfaces []string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+	return l.conflict(id, instance, interfaces)
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeases(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	leases := NewLeases()
+	leases.now = func() time.Time { return now }
+
+	_, err := leases.Acquire(Lease{Holder: "nothing"})
+	require.True(t, errors.Is(err, ErrInvalidLease))
+	_, err = leases.Acquire(Lease{Instance: "test", TTL: -1})
+	require.True(t, errors.Is(err, ErrInvalidLease))
+
+	ci1, err := leases.Acquire(Lease{Holder: "ci-1", Instance: "test", Interfaces: []string{"eth1"}, TTL: 60})
+	require.NoError(t, err)
+	require.Len(t, ci1.ID, 32)
+	require.Equal(t, now.Add(time.Minute), ci1.Expires)
+
+	// The instance and the interfaces are locked for others.
+	_, err = leases.Acquire(Lease{Holder: "ci-2", Instance: "test"})
+	require.True(t, errors.Is(err, ErrLeased))
+	_, err = leases.Acquire(Lease{Holder: "ci-2", Interfaces: []string{"eth2", "eth1"}})
+	require.True(t, errors.Is(err, ErrLeased))
+	require.Contains(t, err.Error(), `interface eth1 is leased by "ci-1"`)
+	ci2, err := leases.Acquire(Lease{Holder: "ci-2", Instance: "other", Interfaces: []string{"eth2"}})
+	require.NoError(t, err)
+	require.Equal(t, now.Add(DefaultLeaseTTL), ci2.Expires)
+
+	require.NoError(t, leases.Check(ci1.ID, "test", []string{"eth1"}))
+	require.True(t, errors.Is(leases.Check("", "test", nil), ErrLeased))
+	require.True(t, errors.Is(leases.Check(ci2.ID, "unleased", []string{"eth1"}), ErrLeased))
+	require.NoError(t, leases.Check("", "unleased", []string{"eth3"}))
+
+	list := leases.List()
+	require.Len(t, list, 2)
+	require.Equal(t, "ci-1", list[0].Holder)
+	require.Empty(t, list[0].ID)
+
+	// Heartbeats keep the lease alive.
+	now = now.Add(50 * time.Second)
+	renewed, err := leases.Renew(ci1.ID)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(time.Minute), renewed.Expires)
+	now = now.Add(50 * time.Second)
+	require.True(t, errors.Is(leases.Check("", "test", nil), ErrLeased))
+
+	// Expired leases are removed.
+	now = now.Add(time.Minute)
+	require.NoError(t, leases.Check("", "test", nil))
+	_, err = leases.Renew(ci1.ID)
+	require.Equal(t, ErrLeaseNotExists, err)
+
+	require.NoError(t, leases.Release(ci2.ID))
+	require.Equal(t, ErrLeaseNotExists, leases.Release(ci2.ID))
+	require.Empty(t, leases.List())
+}
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// validLibraryName returns true for names of files directly in the library folder.
+func validLibraryName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`)
+}
+
+// libraryPath returns the absolute path of a library file.
+func (r *DefaultRepository) libraryPath(name string) string {
+	folder, err := filepath.Abs(r.LibraryFolder())
+	if err != nil {
+		folder = r.LibraryFolder()
+	}
+	return path.Join(folder, name)
+}
+
+// libraryFile returns the absolute path of a library reference (library:<name>),
+// ok is false if the value is no library reference.
+func (r *DefaultRepository) libraryFile(value string) (file string, ok bool) {
+	name, ok := strings.CutPrefix(value, LibraryPrefix)
+	if !ok {
+		return "", false
+	}
+	return r.libraryPath(name), true
+}
+
+// checkLibraryFile returns an error wrapping ErrLibraryFileNotExists if the
+// library reference is invalid or the file does not exist.
+func (r *DefaultRepository) checkLibraryFile(value string) error {
+	name := strings.TrimPrefix(value, LibraryPrefix)
+	if !validLibraryName(name) {
+		return fmt.Errorf("%w: invalid name %q", ErrLibraryFileNotExists, name)
+	}
+	info, err := os.Stat(r.libraryPath(name))
+	if err != nil || !info.Mode().IsRegular() {
+		return fmt.Errorf("%w: %s", ErrLibraryFileNotExists, name)
+	}
+	return nil
+}
+
+// writeResolvedConfig writes the configuration returned by resolvedConfig,
+// which is used instead of the configuration. Nothing is written if the
+// configuration contains no library references.
+func (r *DefaultRepository) writeResolvedConfig(name string, resolved []byte) error {
+	if resolved == nil {
+		return nil
+	}
+	return os.WriteFile(path.Join(r.configFolder, name, RunResolvedConfigFilename), resolved, permission)
+}
+
+// resolvedConfig returns the configuration of the instance with the library
+// references replaced by absolute paths or nil if there are no references.
+func (r *DefaultRepository) resolvedConfig(name string) ([]byte, error) {
+	config, err := r.config(name)
+	if err != nil || !bytes.Contains(config, []byte(LibraryPrefix)) {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	// Keep large numbers like 64 bit identifiers unchanged.
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		// The bngblaster reports the invalid configuration.
+		return nil, nil
+	}
+	resolved := false
+	var resolveErr error
+	var walk func(v interface{}) interface{}
+	walk = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, value := range v {
+				v[key] = walk(value)
+			}
+		case []interface{}:
+			for i, value := range v {
+				v[i] = walk(value)
+			}
+		case string:
+			if file, ok := r.libraryFile(v); ok {
+				if err := r.checkLibraryFile(v); err != nil && resolveErr == nil {
+					resolveErr = err
+				}
+				resolved = true
+				return file
+			}
+		}
+		return v
+	}
+	v = walk(v)
+	if resolveErr != nil || !resolved {
+		return nil, resolveErr
+	}
+	return json.MarshalIndent(v, "", "    ")
+}
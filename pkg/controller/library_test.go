@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRepository_resolvedConfig(t *testing.T) {
+	configFolder := t.TempDir()
+	library := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(library, "full-table.mrt"), []byte("mrt"), permission))
+	r := NewDefaultRepository(WithConfigFolder(configFolder), WithLibraryFolder(library))
+
+	tests := []struct {
+		name         string
+		config       string
+		wantErr      bool
+		wantResolved string
+	}{
+		{
+			name:   "no_references",
+			config: `{"interfaces": {"access": [{"interface": "eth1"}]}}`,
+		}, {
+			name:         "references",
+			config:       `{"bgp": [{"raw-update-file": "library:full-table.mrt", "local-as": 4200000000000}]}`,
+			wantResolved: `{"bgp": [{"raw-update-file": "` + path.Join(library, "full-table.mrt") + `", "local-as": 4200000000000}]}`,
+		}, {
+			name:    "missing",
+			config:  `{"bgp": [{"raw-update-file": "library:missing.mrt"}]}`,
+			wantErr: true,
+		}, {
+			name:    "traversal",
+			config:  `{"bgp": [{"raw-update-file": "library:../config.json"}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, r.Create(tt.name, []byte(tt.config)))
+			config, err := r.resolvedConfig(tt.name)
+			if tt.wantErr {
+				require.True(t, errors.Is(err, ErrLibraryFileNotExists))
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, r.writeResolvedConfig(tt.name, config))
+
+			params := r.commandlineParameters(tt.name, RunningConfig{})
+			resolved := path.Join(configFolder, tt.name, RunResolvedConfigFilename)
+			if tt.wantResolved == "" {
+				require.Equal(t, path.Join(configFolder, tt.name, ConfigFilename), params[2])
+				_, err := os.Stat(resolved)
+				require.True(t, os.IsNotExist(err))
+			} else {
+				require.Equal(t, resolved, params[2])
+				var want, got interface{}
+				require.NoError(t, json.Unmarshal([]byte(tt.wantResolved), &want))
+				require.NoError(t, json.Unmarshal(mustRead(t, resolved), &got))
+				require.Equal(t, want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"time"
+)
+
+const (
+	// StatusStarted is the status of a running instance.
+	StatusStarted = "started"
+	// StatusStopped is the status of an instance, which is not running.
+	StatusStopped = "stopped"
+)
+
+var (
+	// labelName is a label name like team or dut.example.com/model.
+	labelName = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_./-]{0,61}[A-Za-z0-9])?$`)
+	// labelValue is a label value, which could be empty.
+	labelValue = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9_.-]{0,61}[A-Za-z0-9])?)?$`)
+)
+
+// ValidateLabels checks the names and values of the instance labels,
+// which must not contain the characters used by label selectors.
+func ValidateLabels(labels map[string]string) error {
+	for name, value := range labels {
+		if !labelName.MatchString(name) {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		if !labelValue.MatchString(value) {
+			return fmt.Errorf("invalid value %q of label %q", value, name)
+		}
+	}
+	return nil
+}
+
+// metadata reads the metadata of an instance. Instances created before the
+// metadata was introduced use the modification time of the configuration.
+func (r *DefaultRepository) metadata(name string) (Metadata, error) {
+	folder := path.Join(r.configFolder, name)
+	var metadata Metadata
+	b, err := os.ReadFile(path.Join(folder, MetadataFilename))
+	if err == nil {
+		err = json.Unmarshal(b, &metadata)
+	}
+	if metadata.Created.IsZero() {
+		if info, err := os.Stat(path.Join(folder, ConfigFilename)); err == nil {
+			metadata.Created, metadata.Modified = info.ModTime(), info.ModTime()
+		}
+	}
+	return metadata, err
+}
+
+// writeMetadata writes the metadata of an instance with the current time
+// as modification time.
+func (r *DefaultRepository) writeMetadata(name string, metadata Metadata) error {
+	now := time.Now().UTC()
+	if metadata.Created.IsZero() {
+		metadata.Created = now
+	}
+	metadata.Modified = now
+	b, err := json.MarshalIndent(metadata, "", "    ")
+	if err != nil {
+		return err
+	}
+	// Replace the file atomically, it is read concurrently.
+	folder := path.Join(r.configFolder, name)
+	file, err := os.CreateTemp(folder, ".metadata-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(b); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), permission); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path.Join(folder, MetadataFilename))
+}
+
+// SetMetadata implements Repository.
+func (r *DefaultRepository) SetMetadata(name string, metadata Metadata) error {
+	if !r.Exists(name) {
+		return ErrBlasterNotExists
+	}
+	current, _ := r.metadata(name)
+	metadata.Created = current.Created
+	return r.writeMetadata(name, metadata)
+}
+
+// Info implements Repository.
+func (r *DefaultRepository) Info(name string) (InstanceInfo, error) {
+	if !r.Exists(name) {
+		return InstanceInfo{}, ErrBlasterNotExists
+	}
+	// An invalid metadata file is ignored, it is replaced by SetMetadata.
+	metadata, _ := r.metadata(name)
+	info := InstanceInfo{Name: name, Metadata: metadata, Status: StatusStopped}
+	if r.Running(name) {
+		info.Status = StatusStarted
+	}
+	folder := path.Join(r.configFolder, name)
+	if started, err := os.Stat(path.Join(folder, RunConfigFilename)); err == nil {
+		info.LastRun = &RunResult{Started: started.ModTime()}
+		if code, ok := ReadExitCode(folder); ok && info.Status == StatusStopped {
+			info.LastRun.ExitCode = &code
+			if finished, err := os.Stat(path.Join(folder, RunExitFilename)); err == nil {
+				t := finished.ModTime()
+				info.LastRun.Finished = &t
+			}
+		}
+	}
+	return info, nil
+}
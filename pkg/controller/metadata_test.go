@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{name: "empty", labels: nil},
+		{name: "valid", labels: map[string]string{"team": "bng", "dut.example.com/model": "r1-2", "draft": ""}},
+		{name: "invalid_name", labels: map[string]string{"team=x": "bng"}, wantErr: true},
+		{name: "empty_name", labels: map[string]string{"": "bng"}, wantErr: true},
+		{name: "invalid_value", labels: map[string]string{"team": "bng,core"}, wantErr: true},
+		{name: "value_too_long", labels: map[string]string{"team": string(make([]byte, 64))}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateLabels(tt.labels); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultRepository_Info(t *testing.T) {
+	configFolder := t.TempDir()
+	r := NewDefaultRepository(WithConfigFolder(configFolder))
+
+	_, err := r.Info("missing")
+	require.Equal(t, ErrBlasterNotExists, err)
+	require.Equal(t, ErrBlasterNotExists, r.SetMetadata("missing", Metadata{}))
+
+	before := time.Now().Add(-time.Second)
+	require.NoError(t, r.Create("test", []byte("{}")))
+	info, err := r.Info("test")
+	require.NoError(t, err)
+	require.Equal(t, "test", info.Name)
+	require.Equal(t, StatusStopped, info.Status)
+	require.Nil(t, info.LastRun)
+	require.True(t, info.Created.After(before))
+	created := info.Created
+
+	metadata := Metadata{Owner: "alice", Description: "BGP full table", Labels: map[string]string{"team": "bng"}}
+	require.NoError(t, r.SetMetadata("test", metadata))
+	info, err = r.Info("test")
+	require.NoError(t, err)
+	require.Equal(t, "alice", info.Owner)
+	require.Equal(t, "BGP full table", info.Description)
+	require.Equal(t, map[string]string{"team": "bng"}, info.Labels)
+	require.Equal(t, created, info.Created)
+	require.False(t, info.Modified.Before(created))
+
+	// Replacing the configuration keeps the metadata.
+	require.NoError(t, r.Create("test", []byte(`{"interfaces": {}}`)))
+	info, err = r.Info("test")
+	require.NoError(t, err)
+	require.Equal(t, "alice", info.Owner)
+	require.Equal(t, created, info.Created)
+
+	// Last run.
+	folder := path.Join(configFolder, "test")
+	require.NoError(t, os.WriteFile(path.Join(folder, RunConfigFilename), []byte("{}"), permission))
+	info, err = r.Info("test")
+	require.NoError(t, err)
+	require.NotNil(t, info.LastRun)
+	require.Nil(t, info.LastRun.ExitCode)
+	require.NoError(t, os.WriteFile(path.Join(folder, RunExitFilename), []byte("1"), permission))
+	info, err = r.Info("test")
+	require.NoError(t, err)
+	require.NotNil(t, info.LastRun.ExitCode)
+	require.Equal(t, 1, *info.LastRun.ExitCode)
+	require.NotNil(t, info.LastRun.Finished)
+}
+
+func TestDefaultRepository_Info_withoutMetadata(t *testing.T) {
+	configFolder := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(configFolder, "old"), permission))
+	require.NoError(t, os.WriteFile(path.Join(configFolder, "old", ConfigFilename), []byte("{}"), permission))
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path.Join(configFolder, "old", ConfigFilename), modified, modified))
+
+	r := NewDefaultRepository(WithConfigFolder(configFolder))
+	info, err := r.Info("old")
+	require.NoError(t, err)
+	require.True(t, modified.Equal(info.Created))
+	require.True(t, modified.Equal(info.Modified))
+	require.Empty(t, info.Owner)
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const (
+	metricHTTPRequests        = "controller_http_requests_total"
+	metricHTTPRequestDuration = "controller_http_request_duration_seconds"
+	metricCommandDuration     = "controller_socket_command_duration_seconds"
+	metricCommandErrors       = "controller_socket_command_errors_total"
+	metricStartFailures       = "controller_instance_start_failures_total"
+	metricExits               = "controller_instance_exits_total"
+	metricUploadBytes         = "controller_upload_bytes_total"
+
+	labelRoute    = "route"
+	labelMethod   = "method"
+	labelCode     = "code"
+	labelCommand  = "command"
+	labelExitCode = "exit_code"
+)
+
+// Metrics are the self metrics of the controller.
+// All methods can be called on a nil Metrics which does nothing.
+type Metrics struct {
+	HTTPRequests        *prometheus.CounterVec
+	HTTPRequestDuration *prometheus.HistogramVec
+	CommandDuration     *prometheus.HistogramVec
+	CommandErrors       *prometheus.CounterVec
+	StartFailures       prometheus.Counter
+	Exits               *prometheus.CounterVec
+	UploadBytes         prometheus.Counter
+}
+
+// NewMetrics creates the self metrics of the controller.
+func NewMetrics() *Metrics {
+	// Get system hostname.
+	hostname, err := os.Hostname()
+	if err != nil {
+		panic(err)
+	}
+	constLabels := prometheus.Labels{labelHostname: hostname}
+	return &Metrics{
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        metricHTTPRequests,
+			Help:        "The number of HTTP requests per route, method and status code",
+			ConstLabels: constLabels,
+		}, []string{labelRoute, labelMethod, labelCode}),
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        metricHTTPRequestDuration,
+			Help:        "Duration of HTTP requests per route and method",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{labelRoute, labelMethod}),
+		CommandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        metricCommandDuration,
+			Help:        "Duration of socket commands per command",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{labelCommand}),
+		CommandErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        metricCommandErrors,
+			Help:        "The number of failed socket commands per command",
+			ConstLabels: constLabels,
+		}, []string{labelCommand}),
+		StartFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        metricStartFailures,
+			Help:        "The number of instances that failed to start",
+			ConstLabels: constLabels,
+		}),
+		Exits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        metricExits,
+			Help:        "The number of instance exits per exit code (-1 if terminated by a signal)",
+			ConstLabels: constLabels,
+		}, []string{labelExitCode}),
+		UploadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        metricUploadBytes,
+			Help:        "The number of bytes uploaded",
+			ConstLabels: constLabels,
+		}),
+	}
+}
+
+// Register the self metrics together with the go runtime and process collectors.
+func (m *Metrics) Register(registerer prometheus.Registerer) {
+	if m == nil {
+		return
+	}
+	registerer.MustRegister(
+		m.HTTPRequests,
+		m.HTTPRequestDuration,
+		m.CommandDuration,
+		m.CommandErrors,
+		m.StartFailures,
+		m.Exits,
+		m.UploadBytes,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// ObserveHTTPRequest records one HTTP request.
+func (m *Metrics) ObserveHTTPRequest(route string, method string, code int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.HTTPRequests.WithLabelValues(route, method, strconv.Itoa(code)).Inc()
+	m.HTTPRequestDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// ObserveCommand records one socket command.
+func (m *Metrics) ObserveCommand(command string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.CommandDuration.WithLabelValues(command).Observe(duration.Seconds())
+	if err != nil {
+		m.CommandErrors.WithLabelValues(command).Inc()
+	}
+}
+
+// StartFailed records an instance that failed to start.
+func (m *Metrics) StartFailed() {
+	if m == nil {
+		return
+	}
+	m.StartFailures.Inc()
+}
+
+// Exited records the exit code of an instance.
+func (m *Metrics) Exited(code int) {
+	if m == nil {
+		return
+	}
+	m.Exits.WithLabelValues(strconv.Itoa(code)).Inc()
+}
+
+// Uploaded records uploaded bytes.
+func (m *Metrics) Uploaded(bytes int64) {
+	if m == nil {
+		return
+	}
+	m.UploadBytes.Add(float64(bytes))
+}
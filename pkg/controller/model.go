@@ -2,6 +2,8 @@
 // Copyright (C) 2020-2025, RtBrick, Inc.
 package controller
 
+import "time"
+
 //go:generate moq -out repositorymock.go . Repository
 
 // Repository for managing the bng blaster.
@@ -12,6 +14,8 @@ type Repository interface {
 	AllowUpload() bool
 	// Executable returns the bngblaster executable.
 	Executable() string
+	// LibraryFolder returns the folder of the files shared by all instances.
+	LibraryFolder() string
 	// Instances returns a list of all bngblaster instances.
 	Instances() []string
 	// Create a bngblaster instance on the file system.
@@ -22,10 +26,19 @@ type Repository interface {
 	Delete(name string) error
 	// Exists checks if a bngblaster instance exists.
 	Exists(name string) bool
+	// Info returns the metadata, the status and the result of the last run of a bngblaster instance.
+	Info(name string) (InstanceInfo, error)
+	// SetMetadata replaces the owner, description and labels of a bngblaster instance.
+	SetMetadata(name string, metadata Metadata) error
+	// Import replaces the files of a bngblaster instance with the files of folder.
+	// The folder is moved and must be on the same file system as the config folder.
+	Import(name string, folder string) error
 	// Running checks if a bngblaster instance is running.
 	Running(name string) bool
 	// Start the bngblaster instance with the given running configuration.
 	Start(name string, runningConfig RunningConfig) error
+	// Check returns the error Start would return without starting the instance (dry run).
+	Check(name string, runningConfig RunningConfig) error
 	// Stop sends a SIGINT to the instance
 	Stop(name string)
 	// Kill sends a SIGKILL to the instance
@@ -34,6 +47,41 @@ type Repository interface {
 	Command(name string, command SocketCommand) ([]byte, error)
 }
 
+// Metadata describes a bngblaster instance, it is stored next to the configuration.
+type Metadata struct {
+	// Owner of the instance, e.g. a user or a team
+	Owner string `json:"owner,omitempty"`
+	// Description of the instance
+	Description string `json:"description,omitempty"`
+	// Labels to select instances, e.g. team=bng
+	Labels map[string]string `json:"labels,omitempty"`
+	// Created is the time the instance was created
+	Created time.Time `json:"created"`
+	// Modified is the time the configuration or the metadata was changed
+	Modified time.Time `json:"modified"`
+}
+
+// InstanceInfo describes a bngblaster instance and its last run.
+type InstanceInfo struct {
+	// Name of the instance
+	Name string `json:"name"`
+	Metadata
+	// Status of the instance: started|stopped
+	Status string `json:"status"`
+	// LastRun is the result of the last run, if the instance was started
+	LastRun *RunResult `json:"last_run,omitempty"`
+}
+
+// RunResult is the result of a run.
+type RunResult struct {
+	// Started is the time the run was started
+	Started time.Time `json:"started"`
+	// Finished is the time the run was finished, if it is not running
+	Finished *time.Time `json:"finished,omitempty"`
+	// ExitCode of the bngblaster, if the run is finished
+	ExitCode *int `json:"exit_code,omitempty"`
+}
+
 // RunningConfig start configuration for the bngblaster.
 type RunningConfig struct {
 	// Report specifies that a report should be generated
@@ -52,11 +100,39 @@ type RunningConfig struct {
 	PPPoESessionCount int `json:"pppoe_session_count"`
 	// SessionCount overwrites the session count from config
 	SessionCount int `json:"session_count"`
-	// StreamConfig specifies an optional stream configuration file (absolute path)
+	// StreamConfig specifies an optional stream configuration file, either a path
+	// relative to the instance folder (e.g. a file uploaded with _upload), an absolute
+	// path within the instance or library folder or library:<name> for a file of the library
 	StreamConfig string `json:"stream_config"`
 	// MetricFlags flags that allows to specify instance metrics to be reported
-	// Allowed values: session_counters|interfaces|access_interfaces|network_interfaces|a10nsp_interfaces|streams
+	// Allowed values: session_counters|interfaces|access_interfaces|network_interfaces|a10nsp_interfaces|streams|isis|ospf|bgp|ldp|lag
 	MetricFlags []string `json:"metric_flags"`
+	// MetricLabels are additional labels attached to all metrics of the instance,
+	// e.g. test_id, dut, build or user.
+	MetricLabels map[string]string `json:"metric_labels,omitempty"`
+	// StreamMetrics limits the cardinality of the stream metrics.
+	StreamMetrics *StreamMetrics `json:"stream_metrics,omitempty"`
+	// TimeseriesInterval records the counters every n seconds while the instance
+	// is running into the timeseries file (0 disables the recording)
+	TimeseriesInterval int `json:"timeseries_interval,omitempty"`
+	// TimeseriesFlags flags that allows to specify the recorded counters (default: session_counters, interfaces)
+	// Allowed values: session_counters|interfaces|access_interfaces|network_interfaces|a10nsp_interfaces|streams
+	TimeseriesFlags []string `json:"timeseries_flags,omitempty"`
+}
+
+// StreamMetrics controls the cardinality of the stream metrics.
+// Without any option every stream is exported with its own series.
+type StreamMetrics struct {
+	// Aggregate exports the streams summed up by the given labels
+	// instead of one series per stream.
+	// Allowed values: name|direction
+	Aggregate []string `json:"aggregate,omitempty"`
+	// TopN exports only the N streams with the highest rx loss,
+	// it can not be combined with Aggregate.
+	TopN int `json:"top_n,omitempty"`
+	// MaxSeries is the hard limit of exported stream series per instance,
+	// further series are dropped and counted.
+	MaxSeries int `json:"max_series,omitempty"`
 }
 
 // SocketCommand request for a socket command.
@@ -231,18 +307,148 @@ type A10nspInterfacesResponse struct {
 
 // StreamSummaryResponse response for stream-summary socket command.
 type StreamSummaryResponse struct {
-	Code    int `json:"code"`
-	Streams []struct {
-		FlowId    int    `json:"flow-id"`
-		Name      string `json:"name"`
-		Type      string `json:"type"`
-		SubType   string `json:"sub-type"`
-		Direction string `json:"direction"`
-		TxPackets int    `json:"tx-packets"`
-		TxBytes   int    `json:"tx-bytes"`
-		RxPackets int    `json:"rx-packets"`
-		RxBytes   int    `json:"rx-bytes"`
-		RxLoss    int    `json:"rx-loss"`
-		SessionId int    `json:"session-id"`
-	} `json:"stream-summary"`
+	Code    int             `json:"code"`
+	Streams []StreamSummary `json:"stream-summary"`
+}
+
+// StreamSummary is one stream of the stream-summary socket command.
+type StreamSummary struct {
+	FlowId    int    `json:"flow-id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	SubType   string `json:"sub-type"`
+	Direction string `json:"direction"`
+	TxPackets int    `json:"tx-packets"`
+	TxBytes   int    `json:"tx-bytes"`
+	RxPackets int    `json:"rx-packets"`
+	RxBytes   int    `json:"rx-bytes"`
+	RxLoss    int    `json:"rx-loss"`
+	SessionId int    `json:"session-id"`
+}
+
+// CommandResponse common part of all socket command responses.
+type CommandResponse struct {
+	Status  string `json:"status"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// SessionsResponse response for sessions socket command.
+type SessionsResponse struct {
+	Code     int                      `json:"code"`
+	Sessions []map[string]interface{} `json:"sessions"`
+}
+
+// SessionInfoResponse response for session-info socket command.
+type SessionInfoResponse struct {
+	Code        int                    `json:"code"`
+	SessionInfo map[string]interface{} `json:"session-info"`
+}
+
+// StreamInfoResponse response for stream-info socket command.
+type StreamInfoResponse struct {
+	Code       int                    `json:"code"`
+	StreamInfo map[string]interface{} `json:"stream-info"`
+}
+
+// IsisAdjacenciesResponse response for isis-adjacencies socket command.
+type IsisAdjacenciesResponse struct {
+	Code        int `json:"code"`
+	Adjacencies []struct {
+		Interface      string `json:"interface"`
+		Type           string `json:"type"`
+		Level          string `json:"level"`
+		InstanceID     int    `json:"instance-id"`
+		AdjacencyState string `json:"adjacency-state"`
+		Peer           struct {
+			SystemID string `json:"system-id"`
+		} `json:"peer"`
+	} `json:"isis-adjacencies"`
+}
+
+// OspfNeighborsResponse response for ospf-neighbors socket command.
+type OspfNeighborsResponse struct {
+	Code      int `json:"code"`
+	Neighbors []struct {
+		RouterID   string `json:"router-id"`
+		Interface  string `json:"interface"`
+		InstanceID int    `json:"instance-id"`
+		State      string `json:"state"`
+	} `json:"ospf-neighbors"`
+}
+
+// BgpSessionsResponse response for bgp-sessions socket command.
+type BgpSessionsResponse struct {
+	Code     int `json:"code"`
+	Sessions []struct {
+		LocalAddress string `json:"local-address"`
+		PeerAddress  string `json:"peer-address"`
+		LocalAS      int    `json:"local-as"`
+		PeerAS       int    `json:"peer-as"`
+		State        string `json:"state"`
+		Stats        struct {
+			MessageRx int `json:"message-rx"`
+			MessageTx int `json:"message-tx"`
+			UpdateRx  int `json:"update-rx"`
+			UpdateTx  int `json:"update-tx"`
+		} `json:"stats"`
+	} `json:"bgp-sessions"`
+}
+
+// LdpSessionsResponse response for ldp-sessions socket command.
+type LdpSessionsResponse struct {
+	Code     int `json:"code"`
+	Sessions []struct {
+		InstanceID      int    `json:"ldp-instance-id"`
+		LocalAddress    string `json:"local-address"`
+		LocalIdentifier string `json:"local-identifier"`
+		PeerAddress     string `json:"peer-address"`
+		PeerIdentifier  string `json:"peer-identifier"`
+		State           string `json:"state"`
+		Stats           struct {
+			PDURx     int `json:"pdu-rx"`
+			PDUTx     int `json:"pdu-tx"`
+			MessageRx int `json:"messages-rx"`
+			MessageTx int `json:"messages-tx"`
+		} `json:"stats"`
+	} `json:"ldp-sessions"`
+}
+
+// IsisDatabaseResponse response for isis-database socket command.
+type IsisDatabaseResponse struct {
+	Code     int `json:"code"`
+	Database []struct {
+		ID         string `json:"id"`
+		Seq        int    `json:"seq"`
+		Lifetime   int    `json:"lifetime"`
+		SourceType string `json:"source-type"`
+	} `json:"isis-database"`
+}
+
+// OspfDatabaseResponse response for ospf-database socket command.
+type OspfDatabaseResponse struct {
+	Code     int `json:"code"`
+	Database []struct {
+		Type       string `json:"type"`
+		ID         string `json:"id"`
+		Router     string `json:"router"`
+		SourceType string `json:"source-type"`
+	} `json:"ospf-database"`
+}
+
+// LagInfoResponse response for lag-info socket command.
+type LagInfoResponse struct {
+	Code int `json:"code"`
+	Lags []struct {
+		ID            int    `json:"id"`
+		Name          string `json:"name"`
+		State         string `json:"state"`
+		MembersActive int    `json:"members-active"`
+		Members       []struct {
+			Name      string `json:"name"`
+			State     string `json:"state"`
+			TxPackets int    `json:"tx-packets"`
+			RxPackets int    `json:"rx-packets"`
+		} `json:"members"`
+	} `json:"lag-info"`
 }
@@ -19,9 +19,24 @@ func WithExecutable(executable string) DefaultRepositoryOption {
 	}
 }
 
+// WithLibraryFolder is the option to define the folder of the files shared by all
+// instances, the default is the hidden folder .library in the config folder.
+func WithLibraryFolder(folder string) DefaultRepositoryOption {
+	return func(r *DefaultRepository) {
+		r.libraryFolder = folder
+	}
+}
+
 // WithUpload is the option to allow file upload.
 func WithUpload(upload bool) DefaultRepositoryOption {
 	return func(r *DefaultRepository) {
 		r.allow_upload = upload
 	}
 }
+
+// WithMetrics is the option to record the controller self metrics.
+func WithMetrics(metrics *Metrics) DefaultRepositoryOption {
+	return func(r *DefaultRepository) {
+		r.metrics = metrics
+	}
+}
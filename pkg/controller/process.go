@@ -19,7 +19,8 @@ var ExecCommand = exec.Command
 // stdFile file that should be written with the stdout
 // errFile file that should be written with the stderr
 // args first argument will be the command to execute, all the rest are arguments that are used for this command.
-func RunCommand(pidFile string, stdFile string, errFile string, args ...string) (chan bool, error) {
+// The returned channel receives the exit code of the command (-1 if terminated by a signal) and is closed afterwards.
+func RunCommand(pidFile string, stdFile string, errFile string, args ...string) (chan int, error) {
 	if len(args) == 0 {
 		return nil, fmt.Errorf("at least one argument need to be specified")
 	}
@@ -44,12 +45,13 @@ func RunCommand(pidFile string, stdFile string, errFile string, args ...string)
 	pid := cmd.Process.Pid
 	_ = os.WriteFile(pidFile, []byte(fmt.Sprintf("%d", pid)), permission)
 
-	done := make(chan bool)
+	done := make(chan int, 1)
 	go func() {
 		_ = cmd.Wait()
 		_ = stdout.Close()
 		_ = stderr.Close()
 		_ = os.Remove(pidFile)
+		done <- cmd.ProcessState.ExitCode()
 		close(done)
 		log.Info().Str("command", strings.Join(args, " ")).Msg("stopped Command")
 	}()
@@ -7,11 +7,15 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/rs/zerolog/log"
 )
 
@@ -19,6 +23,12 @@ const (
 	metricInstancesTotal   = "instances_total"
 	metricInstancesRunning = "instances_running"
 
+	metricPollDuration = "metrics_poll_duration_seconds"
+	metricPollErrors   = "metrics_poll_errors_total"
+	metricSnapshotAge  = "metrics_snapshot_age_seconds"
+
+	metricStreamSeriesDropped = "metrics_stream_series_dropped"
+
 	metricSessions                     = "sessions"
 	metricSessionsPPPoE                = "sessions_pppoe"
 	metricSessionsIPoE                 = "sessions_ipoe"
@@ -79,6 +89,21 @@ const (
 	metricStreamRxPackets              = "stream_rx_packets"
 	metricStreamRxBytes                = "stream_rx_bytes"
 	metricStreamRxLoss                 = "stream_rx_loss"
+	metricIsisAdjacencyUp              = "isis_adjacency_up"
+	metricOspfNeighborUp               = "ospf_neighbor_up"
+	metricBgpSessionUp                 = "bgp_session_up"
+	metricLdpSessionUp                 = "ldp_session_up"
+	metricIsisDatabaseLSPs             = "isis_database_lsps"
+	metricOspfDatabaseLSAs             = "ospf_database_lsas"
+	metricBgpSessionMessagesRx         = "bgp_session_messages_rx"
+	metricBgpSessionMessagesTx         = "bgp_session_messages_tx"
+	metricBgpSessionUpdatesRx          = "bgp_session_updates_rx"
+	metricBgpSessionUpdatesTx          = "bgp_session_updates_tx"
+	metricLdpSessionMessagesRx         = "ldp_session_messages_rx"
+	metricLdpSessionMessagesTx         = "ldp_session_messages_tx"
+	metricLagUp                        = "lag_up"
+	metricLagMembersActive             = "lag_members_active"
+	metricLagMemberUp                  = "lag_member_up"
 
 	labelHostname        = "hostname"
 	labelInstanceName    = "instance_name"
@@ -90,6 +115,11 @@ const (
 	labelStreamDirection = "stream_direction"
 	labelStreamType      = "stream_type"
 	labelStreamSubType   = "stream_sub_type"
+	labelProtocolID      = "protocol_instance"
+	labelLevel           = "level"
+	labelLocal           = "local"
+	labelPeer            = "peer"
+	labelLagName         = "lag_name"
 )
 
 // Prom defines a prometheus export object.
@@ -97,6 +127,22 @@ type Prom struct {
 	Registry   *prometheus.Registry
 	repository Repository
 
+	// pollInterval enables the background poller if greater than zero.
+	pollInterval time.Duration
+	// doneMutex guards done, which is closed to stop the background poller.
+	doneMutex sync.Mutex
+	done      chan struct{}
+	// snapshot of the instance metrics collected by the background poller.
+	snapshotMutex sync.RWMutex
+	snapshot      map[string]instanceSnapshot
+
+	// Self metrics.
+	PollDuration prometheus.Histogram
+	PollErrors   *prometheus.CounterVec
+	SnapshotAge  *prometheus.Desc
+	// StreamSeriesDropped is the number of stream series currently dropped by the max_series limit.
+	StreamSeriesDropped *prometheus.Desc
+
 	// Metrics.
 	InstancesTotal   *prometheus.Desc
 	InstancesRunning *prometheus.Desc
@@ -163,19 +209,77 @@ type Prom struct {
 	StreamRxPackets *prometheus.Desc
 	StreamRxBytes   *prometheus.Desc
 	StreamRxLoss    *prometheus.Desc
+	// Routing protocols.
+	IsisAdjacencyUp      *prometheus.Desc
+	OspfNeighborUp       *prometheus.Desc
+	BgpSessionUp         *prometheus.Desc
+	LdpSessionUp         *prometheus.Desc
+	IsisDatabaseLSPs     *prometheus.Desc
+	OspfDatabaseLSAs     *prometheus.Desc
+	BgpSessionMessagesRx *prometheus.Desc
+	BgpSessionMessagesTx *prometheus.Desc
+	BgpSessionUpdatesRx  *prometheus.Desc
+	BgpSessionUpdatesTx  *prometheus.Desc
+	LdpSessionMessagesRx *prometheus.Desc
+	LdpSessionMessagesTx *prometheus.Desc
+	// Link aggregation.
+	LagUp            *prometheus.Desc
+	LagMembersActive *prometheus.Desc
+	LagMemberUp      *prometheus.Desc
+}
+
+// instanceSnapshot holds the metrics of one instance collected by the background poller.
+type instanceSnapshot struct {
+	metrics []prometheus.Metric
+	updated time.Time
+}
+
+// PromOption helps to configure the Prom with options.
+type PromOption func(p *Prom)
+
+// WithPollInterval is the option to collect the instance metrics in the background
+// with the given interval instead of collecting them for every scrape.
+func WithPollInterval(interval time.Duration) PromOption {
+	return func(p *Prom) {
+		p.pollInterval = interval
+	}
 }
 
 // NewProm creates a new prometheus export object.
-func NewProm(repository Repository) *Prom {
+func NewProm(repository Repository, opts ...PromOption) *Prom {
 	p := &Prom{
 		Registry:   prometheus.NewRegistry(),
 		repository: repository,
+		snapshot:   map[string]instanceSnapshot{},
+	}
+	for _, opt := range opts {
+		opt(p)
 	}
 	// Get system hostname.
 	hostname, err := os.Hostname()
 	if err != nil {
 		panic(err)
 	}
+	// Self metrics.
+	p.PollDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:        metricPollDuration,
+		Help:        "Duration of collecting the metrics of all running instances",
+		ConstLabels: prometheus.Labels{labelHostname: hostname},
+		Buckets:     prometheus.DefBuckets,
+	})
+	p.PollErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        metricPollErrors,
+		Help:        "The number of errors while collecting instance metrics",
+		ConstLabels: prometheus.Labels{labelHostname: hostname},
+	}, []string{labelInstanceName})
+	p.StreamSeriesDropped = prometheus.NewDesc(metricStreamSeriesDropped,
+		"The number of stream series currently dropped by the max_series limit",
+		[]string{labelInstanceName}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.SnapshotAge = prometheus.NewDesc(metricSnapshotAge,
+		"Age of the metrics snapshot collected by the background poller",
+		[]string{labelInstanceName}, prometheus.Labels{labelHostname: hostname},
+	)
 	// Init metrics.
 	p.InstancesTotal = prometheus.NewDesc(metricInstancesTotal,
 		"The total number of instances",
@@ -428,14 +532,172 @@ func NewProm(repository Repository) *Prom {
 		"Stream RX loss",
 		[]string{labelInstanceName, labelFlowId, labelSessionId, labelStreamName, labelStreamDirection, labelStreamType, labelStreamSubType}, prometheus.Labels{labelHostname: hostname},
 	)
+	// Routing protocols.
+	p.IsisAdjacencyUp = prometheus.NewDesc(metricIsisAdjacencyUp,
+		"ISIS adjacency state (1 = up)",
+		[]string{labelInstanceName, labelProtocolID, labelInterfaceName, labelLevel, labelPeer}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.OspfNeighborUp = prometheus.NewDesc(metricOspfNeighborUp,
+		"OSPF neighbor state (1 = full)",
+		[]string{labelInstanceName, labelProtocolID, labelInterfaceName, labelPeer}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.BgpSessionUp = prometheus.NewDesc(metricBgpSessionUp,
+		"BGP session state (1 = established)",
+		[]string{labelInstanceName, labelLocal, labelPeer}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.LdpSessionUp = prometheus.NewDesc(metricLdpSessionUp,
+		"LDP session state (1 = operational)",
+		[]string{labelInstanceName, labelProtocolID, labelLocal, labelPeer}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.IsisDatabaseLSPs = prometheus.NewDesc(metricIsisDatabaseLSPs,
+		"ISIS LSPs in the database",
+		[]string{labelInstanceName, labelProtocolID, labelLevel}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.OspfDatabaseLSAs = prometheus.NewDesc(metricOspfDatabaseLSAs,
+		"OSPF LSAs in the database",
+		[]string{labelInstanceName, labelProtocolID}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.BgpSessionMessagesRx = prometheus.NewDesc(metricBgpSessionMessagesRx,
+		"BGP session RX messages",
+		[]string{labelInstanceName, labelLocal, labelPeer}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.BgpSessionMessagesTx = prometheus.NewDesc(metricBgpSessionMessagesTx,
+		"BGP session TX messages",
+		[]string{labelInstanceName, labelLocal, labelPeer}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.BgpSessionUpdatesRx = prometheus.NewDesc(metricBgpSessionUpdatesRx,
+		"BGP session RX update messages (routes received)",
+		[]string{labelInstanceName, labelLocal, labelPeer}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.BgpSessionUpdatesTx = prometheus.NewDesc(metricBgpSessionUpdatesTx,
+		"BGP session TX update messages",
+		[]string{labelInstanceName, labelLocal, labelPeer}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.LdpSessionMessagesRx = prometheus.NewDesc(metricLdpSessionMessagesRx,
+		"LDP session RX messages",
+		[]string{labelInstanceName, labelProtocolID, labelLocal, labelPeer}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.LdpSessionMessagesTx = prometheus.NewDesc(metricLdpSessionMessagesTx,
+		"LDP session TX messages",
+		[]string{labelInstanceName, labelProtocolID, labelLocal, labelPeer}, prometheus.Labels{labelHostname: hostname},
+	)
+	// Link aggregation.
+	p.LagUp = prometheus.NewDesc(metricLagUp,
+		"LAG state (1 = up)",
+		[]string{labelInstanceName, labelLagName}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.LagMembersActive = prometheus.NewDesc(metricLagMembersActive,
+		"The number of active LAG members",
+		[]string{labelInstanceName, labelLagName}, prometheus.Labels{labelHostname: hostname},
+	)
+	p.LagMemberUp = prometheus.NewDesc(metricLagMemberUp,
+		"LAG member state (1 = up)",
+		[]string{labelInstanceName, labelLagName, labelInterfaceName}, prometheus.Labels{labelHostname: hostname},
+	)
 
 	// Register all metrics and return.
-	p.Registry.MustRegister(p)
+	p.Registry.MustRegister(p, p.PollDuration, p.PollErrors)
 	return p
 }
 
+// Start the background poller if a poll interval is configured.
+func (p *Prom) Start() {
+	p.doneMutex.Lock()
+	defer p.doneMutex.Unlock()
+	if p.pollInterval <= 0 || p.done != nil {
+		return
+	}
+	p.done = make(chan struct{})
+	go func(done chan struct{}) {
+		ticker := time.NewTicker(p.pollInterval)
+		defer ticker.Stop()
+		p.poll()
+		for {
+			select {
+			case <-ticker.C:
+				p.poll()
+			case <-done:
+				return
+			}
+		}
+	}(p.done)
+}
+
+// Stop the background poller.
+func (p *Prom) Stop() {
+	p.doneMutex.Lock()
+	defer p.doneMutex.Unlock()
+	if p.done != nil {
+		close(p.done)
+		p.done = nil
+	}
+}
+
+// poll collects the metrics of all running instances into a new snapshot.
+func (p *Prom) poll() {
+	timer := prometheus.NewTimer(p.PollDuration)
+	defer timer.ObserveDuration()
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	snapshot := map[string]instanceSnapshot{}
+	for _, instance := range p.runningInstances() {
+		wg.Add(1)
+		go func(instance string) {
+			defer wg.Done()
+			metrics := p.collectInstanceMetrics(instance)
+			mutex.Lock()
+			snapshot[instance] = instanceSnapshot{metrics: metrics, updated: time.Now()}
+			mutex.Unlock()
+		}(instance)
+	}
+	wg.Wait()
+
+	p.snapshotMutex.Lock()
+	p.snapshot = snapshot
+	p.snapshotMutex.Unlock()
+}
+
+// collectInstanceMetrics collects the metrics of one instance into a slice.
+func (p *Prom) collectInstanceMetrics(instance string) []prometheus.Metric {
+	var metrics []prometheus.Metric
+	var wg sync.WaitGroup
+	ch := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		for metric := range ch {
+			metrics = append(metrics, metric)
+		}
+		close(done)
+	}()
+	wg.Add(1)
+	p.collectInstance(&wg, instance, ch)
+	close(ch)
+	<-done
+	return metrics
+}
+
+// runningInstances returns the names of all running instances.
+func (p *Prom) runningInstances() []string {
+	var running []string
+	for _, instance := range p.repository.Instances() {
+		if p.repository.Running(instance) {
+			running = append(running, instance)
+		}
+	}
+	return running
+}
+
+// collectError logs an error while collecting the metrics of an instance.
+func (p *Prom) collectError(instance string, format string, v ...interface{}) {
+	p.PollErrors.WithLabelValues(instance).Inc()
+	log.Warn().Msgf(format, v...)
+}
+
 // Describe all the metrics.
 func (p *Prom) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.SnapshotAge
+	ch <- p.StreamSeriesDropped
 	ch <- p.InstancesTotal
 	ch <- p.InstancesRunning
 	// Session counters.
@@ -500,24 +762,45 @@ func (p *Prom) Describe(ch chan<- *prometheus.Desc) {
 	ch <- p.StreamRxPackets
 	ch <- p.StreamRxBytes
 	ch <- p.StreamRxLoss
+	// Routing protocols.
+	ch <- p.IsisAdjacencyUp
+	ch <- p.OspfNeighborUp
+	ch <- p.BgpSessionUp
+	ch <- p.LdpSessionUp
+	ch <- p.IsisDatabaseLSPs
+	ch <- p.OspfDatabaseLSAs
+	ch <- p.BgpSessionMessagesRx
+	ch <- p.BgpSessionMessagesTx
+	ch <- p.BgpSessionUpdatesRx
+	ch <- p.BgpSessionUpdatesTx
+	ch <- p.LdpSessionMessagesRx
+	ch <- p.LdpSessionMessagesTx
+	// Link aggregation.
+	ch <- p.LagUp
+	ch <- p.LagMembersActive
+	ch <- p.LagMemberUp
 }
 
 // Collect implements required collect function for all metrics collectors.
+// The metrics are served from the snapshot if the background poller is enabled.
 func (p *Prom) Collect(ch chan<- prometheus.Metric) {
+	if p.pollInterval > 0 {
+		p.collectSnapshot(ch)
+		return
+	}
+	timer := prometheus.NewTimer(p.PollDuration)
+	defer timer.ObserveDuration()
 	total := float64(0)
 	running := float64(0)
 
 	var wg sync.WaitGroup
 
-	instances, _ := os.ReadDir(p.repository.ConfigFolder())
-	for _, instance := range instances {
-		if instance.IsDir() {
-			total++
-			if p.repository.Running(instance.Name()) {
-				running++
-				wg.Add(1)
-				go p.collectInstance(&wg, instance.Name(), ch)
-			}
+	for _, instance := range p.repository.Instances() {
+		total++
+		if p.repository.Running(instance) {
+			running++
+			wg.Add(1)
+			go p.collectInstance(&wg, instance, ch)
 		}
 	}
 
@@ -526,6 +809,31 @@ func (p *Prom) Collect(ch chan<- prometheus.Metric) {
 	wg.Wait()
 }
 
+// collectSnapshot serves the metrics from the snapshot of the background poller.
+func (p *Prom) collectSnapshot(ch chan<- prometheus.Metric) {
+	total := float64(0)
+	running := float64(0)
+
+	for _, instance := range p.repository.Instances() {
+		total++
+		if p.repository.Running(instance) {
+			running++
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(p.InstancesTotal, prometheus.GaugeValue, total)
+	ch <- prometheus.MustNewConstMetric(p.InstancesRunning, prometheus.GaugeValue, running)
+
+	p.snapshotMutex.RLock()
+	defer p.snapshotMutex.RUnlock()
+	now := time.Now()
+	for instance, snapshot := range p.snapshot {
+		ch <- prometheus.MustNewConstMetric(p.SnapshotAge, prometheus.GaugeValue, now.Sub(snapshot.updated).Seconds(), instance)
+		for _, metric := range snapshot.metrics {
+			ch <- metric
+		}
+	}
+}
+
 func (p *Prom) collectInstanceSessionCounters(instance string, ch chan<- prometheus.Metric) {
 	// Invoke command.
 	command := SocketCommand{
@@ -533,14 +841,14 @@ func (p *Prom) collectInstanceSessionCounters(instance string, ch chan<- prometh
 	}
 	result, err := p.repository.Command(instance, command)
 	if err != nil {
-		log.Warn().Msgf("failed to execute session-counters: %s", err.Error())
+		p.collectError(instance, "failed to execute session-counters: %s", err.Error())
 		return
 	}
 	// Decode response.
 	var cr SessionCountersResponse
 	err = json.NewDecoder(strings.NewReader(string(result))).Decode(&cr)
 	if err != nil {
-		log.Warn().Msgf("failed to decode session-counters: %s", err.Error())
+		p.collectError(instance, "failed to decode session-counters: %s", err.Error())
 		return
 	}
 	// Return Metrics.
@@ -575,14 +883,14 @@ func (p *Prom) collectInstanceInterfaces(instance string, ch chan<- prometheus.M
 	}
 	result, err := p.repository.Command(instance, command)
 	if err != nil {
-		log.Warn().Msgf("failed to execute interfaces: %s", err.Error())
+		p.collectError(instance, "failed to execute interfaces: %s", err.Error())
 		return
 	}
 	// Decode response.
 	var cr InterfacesResponse
 	err = json.NewDecoder(strings.NewReader(string(result))).Decode(&cr)
 	if err != nil {
-		log.Warn().Msgf("failed to decode interfaces: %s", err.Error())
+		p.collectError(instance, "failed to decode interfaces: %s", err.Error())
 		return
 	}
 	// Return Metrics.
@@ -601,14 +909,14 @@ func (p *Prom) collectInstanceAccessInterfaces(instance string, ch chan<- promet
 	}
 	result, err := p.repository.Command(instance, command)
 	if err != nil {
-		log.Warn().Msgf("failed to execute access-interfaces: %s", err.Error())
+		p.collectError(instance, "failed to execute access-interfaces: %s", err.Error())
 		return
 	}
 	// Decode response.
 	var cr AccessInterfacesResponse
 	err = json.NewDecoder(strings.NewReader(string(result))).Decode(&cr)
 	if err != nil {
-		log.Warn().Msgf("failed to decode access-interfaces: %s", err.Error())
+		p.collectError(instance, "failed to decode access-interfaces: %s", err.Error())
 		return
 	}
 	// Return Metrics.
@@ -654,14 +962,14 @@ func (p *Prom) collectInstanceNetworkInterfaces(instance string, ch chan<- prome
 	}
 	result, err := p.repository.Command(instance, command)
 	if err != nil {
-		log.Warn().Msgf("failed to execute network-interfaces: %s", err.Error())
+		p.collectError(instance, "failed to execute network-interfaces: %s", err.Error())
 		return
 	}
 	// Decode response.
 	var cr NetworkInterfacesResponse
 	err = json.NewDecoder(strings.NewReader(string(result))).Decode(&cr)
 	if err != nil {
-		log.Warn().Msgf("failed to decode network-interfaces: %s", err.Error())
+		p.collectError(instance, "failed to decode network-interfaces: %s", err.Error())
 		return
 	}
 	// Return Metrics.
@@ -706,14 +1014,14 @@ func (p *Prom) collectInstanceA10nspInterfaces(instance string, ch chan<- promet
 	}
 	result, err := p.repository.Command(instance, command)
 	if err != nil {
-		log.Warn().Msgf("failed to execute a10nsp-interfaces: %s", err.Error())
+		p.collectError(instance, "failed to execute a10nsp-interfaces: %s", err.Error())
 		return
 	}
 	// Decode response.
 	var cr A10nspInterfacesResponse
 	err = json.NewDecoder(strings.NewReader(string(result))).Decode(&cr)
 	if err != nil {
-		log.Warn().Msgf("failed to decode a10nsp-interfaces: %s", err.Error())
+		p.collectError(instance, "failed to decode a10nsp-interfaces: %s", err.Error())
 		return
 	}
 	// Return Metrics.
@@ -749,33 +1057,281 @@ func (p *Prom) collectInstanceA10nspInterfaces(instance string, ch chan<- promet
 	}
 }
 
-func (p *Prom) collectInstanceStreams(instance string, ch chan<- prometheus.Metric) {
+func (p *Prom) collectInstanceStreams(instance string, config *StreamMetrics, ch chan<- prometheus.Metric) {
 	// Invoke command.
 	command := SocketCommand{
 		Command: "stream-summary",
 	}
 	result, err := p.repository.Command(instance, command)
 	if err != nil {
-		log.Warn().Msgf("failed to execute stream-summary: %s", err.Error())
+		p.collectError(instance, "failed to execute stream-summary: %s", err.Error())
 		return
 	}
 	// Decode response.
 	var cr StreamSummaryResponse
 	err = json.NewDecoder(strings.NewReader(string(result))).Decode(&cr)
 	if err != nil {
-		log.Warn().Msgf("failed to decode stream-summary: %s", err.Error())
+		p.collectError(instance, "failed to decode stream-summary: %s", err.Error())
 		return
 	}
+	if config == nil {
+		config = &StreamMetrics{}
+	}
 	// Return Metrics.
-	for _, stream := range cr.Streams {
-		fid := strconv.Itoa(stream.FlowId)
-		sid := strconv.Itoa(stream.SessionId)
+	streams := cr.Streams
+	if len(config.Aggregate) > 0 {
+		streams = aggregateStreams(cr.Streams, config.Aggregate)
+	} else if config.TopN > 0 {
+		streams = topStreams(cr.Streams, config.TopN)
+	}
+	const seriesPerStream = 5
+	dropped := 0
+	for i, stream := range streams {
+		if config.MaxSeries > 0 && (i+1)*seriesPerStream > config.MaxSeries {
+			dropped = (len(streams) - i) * seriesPerStream
+			break
+		}
+		fid, sid := "", ""
+		if stream.FlowId > 0 {
+			fid = strconv.Itoa(stream.FlowId)
+			sid = strconv.Itoa(stream.SessionId)
+		}
 		ch <- prometheus.MustNewConstMetric(p.StreamTxPackets, prometheus.CounterValue, float64(stream.TxPackets), instance, fid, sid, stream.Name, stream.Direction, stream.Type, stream.SubType)
 		ch <- prometheus.MustNewConstMetric(p.StreamTxBytes, prometheus.CounterValue, float64(stream.TxBytes), instance, fid, sid, stream.Name, stream.Direction, stream.Type, stream.SubType)
 		ch <- prometheus.MustNewConstMetric(p.StreamRxPackets, prometheus.CounterValue, float64(stream.RxPackets), instance, fid, sid, stream.Name, stream.Direction, stream.Type, stream.SubType)
 		ch <- prometheus.MustNewConstMetric(p.StreamRxBytes, prometheus.CounterValue, float64(stream.RxBytes), instance, fid, sid, stream.Name, stream.Direction, stream.Type, stream.SubType)
 		ch <- prometheus.MustNewConstMetric(p.StreamRxLoss, prometheus.CounterValue, float64(stream.RxLoss), instance, fid, sid, stream.Name, stream.Direction, stream.Type, stream.SubType)
 	}
+	ch <- prometheus.MustNewConstMetric(p.StreamSeriesDropped, prometheus.GaugeValue, float64(dropped), instance)
+}
+
+// aggregateStreams sums up the streams by the given labels (name and/or direction).
+// The aggregated streams have no flow id, the labels not aggregated by are empty.
+func aggregateStreams(streams []StreamSummary, by []string) []StreamSummary {
+	var byName, byDirection bool
+	for _, label := range by {
+		switch label {
+		case "name":
+			byName = true
+		case "direction":
+			byDirection = true
+		}
+	}
+	var aggregated []StreamSummary
+	index := map[[2]string]int{}
+	for _, stream := range streams {
+		var key StreamSummary
+		if byName {
+			key.Name = stream.Name
+		}
+		if byDirection {
+			key.Direction = stream.Direction
+		}
+		i, ok := index[[2]string{key.Name, key.Direction}]
+		if !ok {
+			i = len(aggregated)
+			index[[2]string{key.Name, key.Direction}] = i
+			aggregated = append(aggregated, key)
+		}
+		aggregated[i].TxPackets += stream.TxPackets
+		aggregated[i].TxBytes += stream.TxBytes
+		aggregated[i].RxPackets += stream.RxPackets
+		aggregated[i].RxBytes += stream.RxBytes
+		aggregated[i].RxLoss += stream.RxLoss
+	}
+	return aggregated
+}
+
+// topStreams returns the n streams with the highest rx loss.
+func topStreams(streams []StreamSummary, n int) []StreamSummary {
+	top := make([]StreamSummary, len(streams))
+	copy(top, streams)
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].RxLoss > top[j].RxLoss
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// stateValue returns 1 if the state is equal to the up state and 0 otherwise.
+func stateValue(state string, up string) float64 {
+	if strings.EqualFold(state, up) {
+		return 1
+	}
+	return 0
+}
+
+func (p *Prom) collectInstanceIsis(instance string, ch chan<- prometheus.Metric) {
+	// Invoke command.
+	command := SocketCommand{
+		Command: "isis-adjacencies",
+	}
+	var cr IsisAdjacenciesResponse
+	if !p.command(instance, command, &cr) {
+		return
+	}
+	// Return Metrics.
+	instances := p.protocolInstances(instance, "isis")
+	for _, adjacency := range cr.Adjacencies {
+		id := strconv.Itoa(adjacency.InstanceID)
+		ch <- prometheus.MustNewConstMetric(p.IsisAdjacencyUp, prometheus.GaugeValue, stateValue(adjacency.AdjacencyState, "Up"), instance, id, adjacency.Interface, adjacency.Level, adjacency.Peer.SystemID)
+		if _, ok := instances[adjacency.InstanceID]; !ok {
+			instances[adjacency.InstanceID] = isisLevels
+		}
+	}
+	// Count the LSPs per protocol instance and level, also if all adjacencies are down.
+	for id, levels := range instances {
+		for _, level := range []int{1, 2} {
+			if levels&level == 0 {
+				continue
+			}
+			command := SocketCommand{
+				Command:   "isis-database",
+				Arguments: map[string]interface{}{"instance": id, "level": level},
+			}
+			var db IsisDatabaseResponse
+			if !p.command(instance, command, &db) {
+				continue
+			}
+			// Same level encoding as the isis-adjacencies (L1, L2).
+			ch <- prometheus.MustNewConstMetric(p.IsisDatabaseLSPs, prometheus.GaugeValue, float64(len(db.Database)), instance, strconv.Itoa(id), "L"+strconv.Itoa(level))
+		}
+	}
+}
+
+// isisLevels is the default ISIS level of a protocol instance (level 1 and 2).
+const isisLevels = 3
+
+// protocolInstances returns the instance ids of the protocol (isis or ospf)
+// configured for the instance together with the configured ISIS level.
+func (p *Prom) protocolInstances(instance string, protocol string) map[int]int {
+	instances := map[int]int{}
+	config, err := os.ReadFile(path.Join(p.repository.ConfigFolder(), instance, ConfigFilename))
+	if err != nil {
+		return instances
+	}
+	var c map[string]json.RawMessage
+	if err := json.Unmarshal(config, &c); err != nil {
+		return instances
+	}
+	var sections []struct {
+		InstanceID int `json:"instance-id"`
+		Level      int `json:"level"`
+	}
+	if err := json.Unmarshal(c[protocol], &sections); err != nil {
+		return instances
+	}
+	for _, section := range sections {
+		if section.Level == 0 {
+			section.Level = isisLevels
+		}
+		instances[section.InstanceID] = section.Level
+	}
+	return instances
+}
+
+func (p *Prom) collectInstanceOspf(instance string, ch chan<- prometheus.Metric) {
+	// Invoke command.
+	command := SocketCommand{
+		Command: "ospf-neighbors",
+	}
+	var cr OspfNeighborsResponse
+	if !p.command(instance, command, &cr) {
+		return
+	}
+	// Return Metrics.
+	instances := p.protocolInstances(instance, "ospf")
+	for _, neighbor := range cr.Neighbors {
+		id := strconv.Itoa(neighbor.InstanceID)
+		ch <- prometheus.MustNewConstMetric(p.OspfNeighborUp, prometheus.GaugeValue, stateValue(neighbor.State, "Full"), instance, id, neighbor.Interface, neighbor.RouterID)
+		instances[neighbor.InstanceID] = 0
+	}
+	// Count the LSAs per protocol instance, also if all neighbors are down.
+	for id := range instances {
+		command := SocketCommand{
+			Command:   "ospf-database",
+			Arguments: map[string]interface{}{"instance": id},
+		}
+		var db OspfDatabaseResponse
+		if !p.command(instance, command, &db) {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(p.OspfDatabaseLSAs, prometheus.GaugeValue, float64(len(db.Database)), instance, strconv.Itoa(id))
+	}
+}
+
+func (p *Prom) collectInstanceBgp(instance string, ch chan<- prometheus.Metric) {
+	// Invoke command.
+	command := SocketCommand{
+		Command: "bgp-sessions",
+	}
+	var cr BgpSessionsResponse
+	if !p.command(instance, command, &cr) {
+		return
+	}
+	// Return Metrics.
+	for _, session := range cr.Sessions {
+		ch <- prometheus.MustNewConstMetric(p.BgpSessionUp, prometheus.GaugeValue, stateValue(session.State, "established"), instance, session.LocalAddress, session.PeerAddress)
+		ch <- prometheus.MustNewConstMetric(p.BgpSessionMessagesRx, prometheus.CounterValue, float64(session.Stats.MessageRx), instance, session.LocalAddress, session.PeerAddress)
+		ch <- prometheus.MustNewConstMetric(p.BgpSessionMessagesTx, prometheus.CounterValue, float64(session.Stats.MessageTx), instance, session.LocalAddress, session.PeerAddress)
+		ch <- prometheus.MustNewConstMetric(p.BgpSessionUpdatesRx, prometheus.CounterValue, float64(session.Stats.UpdateRx), instance, session.LocalAddress, session.PeerAddress)
+		ch <- prometheus.MustNewConstMetric(p.BgpSessionUpdatesTx, prometheus.CounterValue, float64(session.Stats.UpdateTx), instance, session.LocalAddress, session.PeerAddress)
+	}
+}
+
+func (p *Prom) collectInstanceLdp(instance string, ch chan<- prometheus.Metric) {
+	// Invoke command.
+	command := SocketCommand{
+		Command: "ldp-sessions",
+	}
+	var cr LdpSessionsResponse
+	if !p.command(instance, command, &cr) {
+		return
+	}
+	// Return Metrics.
+	for _, session := range cr.Sessions {
+		id := strconv.Itoa(session.InstanceID)
+		ch <- prometheus.MustNewConstMetric(p.LdpSessionUp, prometheus.GaugeValue, stateValue(session.State, "operational"), instance, id, session.LocalIdentifier, session.PeerIdentifier)
+		ch <- prometheus.MustNewConstMetric(p.LdpSessionMessagesRx, prometheus.CounterValue, float64(session.Stats.MessageRx), instance, id, session.LocalIdentifier, session.PeerIdentifier)
+		ch <- prometheus.MustNewConstMetric(p.LdpSessionMessagesTx, prometheus.CounterValue, float64(session.Stats.MessageTx), instance, id, session.LocalIdentifier, session.PeerIdentifier)
+	}
+}
+
+func (p *Prom) collectInstanceLag(instance string, ch chan<- prometheus.Metric) {
+	// Invoke command.
+	command := SocketCommand{
+		Command: "lag-info",
+	}
+	var cr LagInfoResponse
+	if !p.command(instance, command, &cr) {
+		return
+	}
+	// Return Metrics.
+	for _, lag := range cr.Lags {
+		ch <- prometheus.MustNewConstMetric(p.LagUp, prometheus.GaugeValue, stateValue(lag.State, "Up"), instance, lag.Name)
+		ch <- prometheus.MustNewConstMetric(p.LagMembersActive, prometheus.GaugeValue, float64(lag.MembersActive), instance, lag.Name)
+		for _, member := range lag.Members {
+			ch <- prometheus.MustNewConstMetric(p.LagMemberUp, prometheus.GaugeValue, stateValue(member.State, "Up"), instance, lag.Name, member.Name)
+		}
+	}
+}
+
+// command invokes the socket command and decodes the response into v.
+// Errors are logged and false is returned.
+func (p *Prom) command(instance string, command SocketCommand, v interface{}) bool {
+	result, err := p.repository.Command(instance, command)
+	if err != nil {
+		p.collectError(instance, "failed to execute %s: %s", command.Command, err.Error())
+		return false
+	}
+	err = json.NewDecoder(strings.NewReader(string(result))).Decode(v)
+	if err != nil {
+		p.collectError(instance, "failed to decode %s: %s", command.Command, err.Error())
+		return false
+	}
+	return true
 }
 
 func (p *Prom) collectInstance(wg *sync.WaitGroup, instance string, ch chan<- prometheus.Metric) {
@@ -785,7 +1341,7 @@ func (p *Prom) collectInstance(wg *sync.WaitGroup, instance string, ch chan<- pr
 	path := path.Join(folder, RunConfigFilename)
 	file, err := os.Open(path)
 	if err != nil {
-		log.Warn().Msgf("failed to open %s: %s", path, err.Error())
+		p.collectError(instance, "failed to open %s: %s", path, err.Error())
 		fmt.Println(err)
 		return
 	}
@@ -793,9 +1349,29 @@ func (p *Prom) collectInstance(wg *sync.WaitGroup, instance string, ch chan<- pr
 	var runningConfig RunningConfig
 	err = json.NewDecoder(file).Decode(&runningConfig)
 	if err != nil {
-		log.Warn().Msgf("failed to decode %s: %s", path, err.Error())
+		p.collectError(instance, "failed to decode %s: %s", path, err.Error())
 		return
 	}
+	if len(runningConfig.MetricLabels) > 0 {
+		if err := ValidateMetricLabels(runningConfig.MetricLabels); err != nil {
+			p.collectError(instance, "invalid metric labels in %s: %s", path, err.Error())
+			return
+		}
+		labeled := make(chan prometheus.Metric)
+		done := make(chan struct{})
+		go func(out chan<- prometheus.Metric) {
+			labels := metricLabelPairs(runningConfig.MetricLabels)
+			for metric := range labeled {
+				out <- labeledMetric{Metric: metric, labels: labels}
+			}
+			close(done)
+		}(ch)
+		defer func() {
+			close(labeled)
+			<-done
+		}()
+		ch = labeled
+	}
 
 	for _, flag := range runningConfig.MetricFlags {
 		switch flag {
@@ -810,9 +1386,104 @@ func (p *Prom) collectInstance(wg *sync.WaitGroup, instance string, ch chan<- pr
 		case "a10nsp_interfaces":
 			p.collectInstanceA10nspInterfaces(instance, ch)
 		case "streams":
-			p.collectInstanceStreams(instance, ch)
+			p.collectInstanceStreams(instance, runningConfig.StreamMetrics, ch)
+		case "isis":
+			p.collectInstanceIsis(instance, ch)
+		case "ospf":
+			p.collectInstanceOspf(instance, ch)
+		case "bgp":
+			p.collectInstanceBgp(instance, ch)
+		case "ldp":
+			p.collectInstanceLdp(instance, ch)
+		case "lag":
+			p.collectInstanceLag(instance, ch)
 		default:
 			log.Warn().Msgf("unknown metrics flag: %s", flag)
 		}
 	}
 }
+
+// metricLabelName are the valid names of user defined metric labels.
+var metricLabelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// reservedMetricLabels are the label names used by the exported metrics.
+var reservedMetricLabels = map[string]bool{
+	labelHostname:        true,
+	labelInstanceName:    true,
+	labelInterfaceName:   true,
+	labelInterfaceType:   true,
+	labelSessionId:       true,
+	labelFlowId:          true,
+	labelStreamName:      true,
+	labelStreamDirection: true,
+	labelStreamType:      true,
+	labelStreamSubType:   true,
+	labelProtocolID:      true,
+	labelLevel:           true,
+	labelLocal:           true,
+	labelPeer:            true,
+	labelLagName:         true,
+}
+
+// ValidateMetricLabels checks that the user defined metric labels are valid
+// prometheus label names and do not collide with the exported labels.
+func ValidateMetricLabels(labels map[string]string) error {
+	for name := range labels {
+		if !metricLabelName.MatchString(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("invalid metric label name %q", name)
+		}
+		if reservedMetricLabels[name] {
+			return fmt.Errorf("metric label name %q is reserved", name)
+		}
+	}
+	return nil
+}
+
+// ValidateStreamMetrics checks the stream metrics cardinality options.
+func ValidateStreamMetrics(config *StreamMetrics) error {
+	if config == nil {
+		return nil
+	}
+	for _, label := range config.Aggregate {
+		if label != "name" && label != "direction" {
+			return fmt.Errorf("invalid stream metrics aggregate %q", label)
+		}
+	}
+	if config.TopN < 0 || config.MaxSeries < 0 {
+		return fmt.Errorf("stream metrics top_n and max_series must not be negative")
+	}
+	// The aggregated and the top streams share the metrics, summing them up would
+	// count the traffic twice.
+	if len(config.Aggregate) > 0 && config.TopN > 0 {
+		return fmt.Errorf("stream metrics aggregate and top_n are mutually exclusive")
+	}
+	return nil
+}
+
+// metricLabelPairs converts the user defined metric labels into label pairs.
+func metricLabelPairs(labels map[string]string) []*dto.LabelPair {
+	pairs := make([]*dto.LabelPair, 0, len(labels))
+	for name, value := range labels {
+		name, value := name, value
+		pairs = append(pairs, &dto.LabelPair{Name: &name, Value: &value})
+	}
+	return pairs
+}
+
+// labeledMetric attaches the user defined metric labels of an instance to a metric.
+type labeledMetric struct {
+	prometheus.Metric
+	labels []*dto.LabelPair
+}
+
+// Write implements prometheus.Metric.
+func (m labeledMetric) Write(out *dto.Metric) error {
+	if err := m.Metric.Write(out); err != nil {
+		return err
+	}
+	out.Label = append(out.Label, m.labels...)
+	sort.Slice(out.Label, func(i, j int) bool {
+		return out.Label[i].GetName() < out.Label[j].GetName()
+	})
+	return nil
+}
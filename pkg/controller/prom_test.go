@@ -0,0 +1,367 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// promCommandResults are the canned socket command responses used by the prometheus tests.
+var promCommandResults = map[string]string{
+	"session-counters": `{"code": 200, "session-counters": {"sessions": 10, "sessions-established": 8}}`,
+	"stream-summary": `{"code": 200, "stream-summary": [
+		{"flow-id": 1, "name": "S1", "direction": "upstream", "session-id": 1, "rx-loss": 5},
+		{"flow-id": 2, "name": "S1", "direction": "downstream", "session-id": 1, "rx-loss": 0}
+	]}`,
+	"isis-adjacencies": `{"code": 200, "isis-adjacencies": [
+		{"interface": "eth1", "level": "L1", "instance-id": 1, "adjacency-state": "Up", "peer": {"system-id": "0100.1001.0011"}},
+		{"interface": "eth2", "level": "L2", "instance-id": 1, "adjacency-state": "Init", "peer": {"system-id": "0100.1001.0012"}}
+	]}`,
+	"isis-database": `{"code": 200, "isis-database": [{"id": "0000.0000.0001.00-00"}, {"id": "0000.0000.0002.00-00"}]}`,
+	"bgp-sessions": `{"code": 200, "bgp-sessions": [
+		{"local-address": "10.0.0.1", "peer-address": "10.0.0.2", "state": "established", "stats": {"update-tx": 42}}
+	]}`,
+	"lag-info": `{"code": 200, "lag-info": [
+		{"name": "lag1", "state": "Up", "members-active": 1, "members": [{"name": "eth1", "state": "Up"}, {"name": "eth2", "state": "Down"}]}
+	]}`,
+}
+
+// newPromTestRepository returns a repository mock with one running instance
+// that has the given metric flags configured.
+func newPromTestRepository(t *testing.T, runningConfig RunningConfig) *RepositoryMock {
+	t.Helper()
+	folder := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(folder, "test"), permission))
+	require.NoError(t, os.MkdirAll(path.Join(folder, defaultLibraryFolder), permission))
+	config, err := json.Marshal(runningConfig)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path.Join(folder, "test", RunConfigFilename), config, permission))
+	// ISIS instance 2 has no adjacencies.
+	isis := `{"isis": [{"instance-id": 1}, {"instance-id": 2, "level": 1}]}`
+	require.NoError(t, os.WriteFile(path.Join(folder, "test", ConfigFilename), []byte(isis), permission))
+
+	return &RepositoryMock{
+		ConfigFolderFunc: func() string {
+			return folder
+		},
+		InstancesFunc: func() []string {
+			return NewDefaultRepository(WithConfigFolder(folder)).Instances()
+		},
+		RunningFunc: func(name string) bool {
+			return true
+		},
+		CommandFunc: func(name string, command SocketCommand) ([]byte, error) {
+			result, ok := promCommandResults[command.Command]
+			if !ok {
+				return nil, fmt.Errorf("unknown command %s", command.Command)
+			}
+			return []byte(result), nil
+		},
+	}
+}
+
+// gatherValue returns the value of the metric with the given name and
+// labels or fails the test if no such metric was gathered.
+func gatherValue(t *testing.T, p *Prom, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := p.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			got := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				got[label.GetName()] = label.GetValue()
+			}
+			for k, v := range labels {
+				if got[k] != v {
+					continue metrics
+				}
+			}
+			switch {
+			case metric.GetGauge() != nil:
+				return metric.GetGauge().GetValue()
+			case metric.GetCounter() != nil:
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	t.Fatalf("metric %s %v not found", name, labels)
+	return 0
+}
+
+func TestProm_Collect(t *testing.T) {
+	repository := newPromTestRepository(t, RunningConfig{
+		MetricFlags: []string{"session_counters", "streams", "isis", "bgp", "lag"},
+	})
+	p := NewProm(repository)
+
+	tests := []struct {
+		name   string
+		metric string
+		labels map[string]string
+		want   float64
+	}{
+		{
+			name:   "instances_total",
+			metric: metricInstancesTotal,
+			want:   1,
+		}, {
+			name:   "instances_running",
+			metric: metricInstancesRunning,
+			want:   1,
+		}, {
+			name:   "sessions_established",
+			metric: metricSessionsEstablished,
+			labels: map[string]string{labelInstanceName: "test"},
+			want:   8,
+		}, {
+			name:   "stream_rx_loss",
+			metric: metricStreamRxLoss,
+			labels: map[string]string{labelFlowId: "1"},
+			want:   5,
+		}, {
+			name:   "isis_adjacency_up",
+			metric: metricIsisAdjacencyUp,
+			labels: map[string]string{labelInterfaceName: "eth1", labelLevel: "L1"},
+			want:   1,
+		}, {
+			name:   "isis_adjacency_down",
+			metric: metricIsisAdjacencyUp,
+			labels: map[string]string{labelInterfaceName: "eth2", labelLevel: "L2"},
+			want:   0,
+		}, {
+			name:   "isis_database_lsps",
+			metric: metricIsisDatabaseLSPs,
+			labels: map[string]string{labelProtocolID: "1", labelLevel: "L2"},
+			want:   2,
+		}, {
+			name:   "isis_database_lsps_without_adjacencies",
+			metric: metricIsisDatabaseLSPs,
+			labels: map[string]string{labelProtocolID: "2", labelLevel: "L1"},
+			want:   2,
+		}, {
+			name:   "bgp_session_up",
+			metric: metricBgpSessionUp,
+			labels: map[string]string{labelPeer: "10.0.0.2"},
+			want:   1,
+		}, {
+			name:   "bgp_session_updates_tx",
+			metric: metricBgpSessionUpdatesTx,
+			labels: map[string]string{labelPeer: "10.0.0.2"},
+			want:   42,
+		}, {
+			name:   "lag_member_down",
+			metric: metricLagMemberUp,
+			labels: map[string]string{labelLagName: "lag1", labelInterfaceName: "eth2"},
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gatherValue(t, p, tt.metric, tt.labels)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProm_CollectUnknownFlag(t *testing.T) {
+	repository := newPromTestRepository(t, RunningConfig{
+		MetricFlags: []string{"unknown"},
+	})
+	p := NewProm(repository)
+	families, err := p.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		name := family.GetName()
+		require.True(t, strings.HasPrefix(name, "instances_") || strings.HasPrefix(name, "metrics_"), name)
+	}
+}
+
+func TestProm_Poll(t *testing.T) {
+	repository := newPromTestRepository(t, RunningConfig{
+		MetricFlags: []string{"session_counters"},
+	})
+	p := NewProm(repository, WithPollInterval(time.Hour))
+
+	// Nothing is collected before the first poll.
+	families, err := p.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		require.NotEqual(t, metricSessions, family.GetName())
+	}
+	require.Empty(t, repository.CommandCalls())
+
+	p.poll()
+	calls := len(repository.CommandCalls())
+	require.Equal(t, float64(10), gatherValue(t, p, metricSessions, map[string]string{labelInstanceName: "test"}))
+	require.GreaterOrEqual(t, gatherValue(t, p, metricSnapshotAge, map[string]string{labelInstanceName: "test"}), float64(0))
+	// Scrapes are served from the snapshot.
+	require.Equal(t, calls, len(repository.CommandCalls()))
+}
+
+func TestProm_StartStop(t *testing.T) {
+	repository := newPromTestRepository(t, RunningConfig{})
+	p := NewProm(repository, WithPollInterval(time.Millisecond))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			p.Start()
+		}()
+		go func() {
+			defer wg.Done()
+			p.Stop()
+		}()
+	}
+	wg.Wait()
+	p.Stop()
+	// Stopping a stopped poller does nothing.
+	p.Stop()
+}
+
+func TestProm_CollectMetricLabels(t *testing.T) {
+	repository := newPromTestRepository(t, RunningConfig{
+		MetricFlags:  []string{"session_counters", "bgp"},
+		MetricLabels: map[string]string{"test_id": "42", "dut": "leaf1"},
+	})
+	p := NewProm(repository)
+
+	labels := map[string]string{labelInstanceName: "test", "test_id": "42", "dut": "leaf1"}
+	require.Equal(t, float64(8), gatherValue(t, p, metricSessionsEstablished, labels))
+	labels[labelPeer] = "10.0.0.2"
+	require.Equal(t, float64(1), gatherValue(t, p, metricBgpSessionUp, labels))
+}
+
+func TestValidateMetricLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "valid", labels: map[string]string{"test_id": "1", "dut": "leaf1"}},
+		{name: "invalid_name", labels: map[string]string{"test-id": "1"}, wantErr: true},
+		{name: "internal_name", labels: map[string]string{"__name__": "x"}, wantErr: true},
+		{name: "reserved", labels: map[string]string{labelFlowId: "1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetricLabels(tt.labels)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateStreamMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *StreamMetrics
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "valid", config: &StreamMetrics{Aggregate: []string{"name"}, MaxSeries: 100}},
+		{name: "invalid_aggregate", config: &StreamMetrics{Aggregate: []string{"flow_id"}}, wantErr: true},
+		{name: "negative", config: &StreamMetrics{TopN: -1}, wantErr: true},
+		{name: "aggregate_and_top_n", config: &StreamMetrics{Aggregate: []string{"name"}, TopN: 10}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStreamMetrics(tt.config)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProm_CollectStreamMetrics(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *StreamMetrics
+		wantFlowIDs []string
+		wantLoss    float64
+		wantDropped float64
+	}{
+		{
+			name:        "all",
+			wantFlowIDs: []string{"1", "2"},
+			wantLoss:    5,
+		}, {
+			name:        "aggregate_name",
+			config:      &StreamMetrics{Aggregate: []string{"name"}},
+			wantFlowIDs: []string{""},
+			wantLoss:    5,
+		}, {
+			name:        "aggregate_direction",
+			config:      &StreamMetrics{Aggregate: []string{"direction"}},
+			wantFlowIDs: []string{"", ""},
+			wantLoss:    5,
+		}, {
+			name:        "top_n",
+			config:      &StreamMetrics{TopN: 1},
+			wantFlowIDs: []string{"1"},
+			wantLoss:    5,
+		}, {
+			name:        "max_series",
+			config:      &StreamMetrics{MaxSeries: 7},
+			wantFlowIDs: []string{"1"},
+			wantLoss:    5,
+			wantDropped: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newPromTestRepository(t, RunningConfig{
+				MetricFlags:   []string{"streams"},
+				StreamMetrics: tt.config,
+			})
+			p := NewProm(repository)
+			families, err := p.Registry.Gather()
+			require.NoError(t, err)
+			var flowIDs []string
+			loss := float64(0)
+			for _, family := range families {
+				if family.GetName() != metricStreamRxLoss {
+					continue
+				}
+				for _, metric := range family.GetMetric() {
+					for _, label := range metric.GetLabel() {
+						if label.GetName() == labelFlowId {
+							flowIDs = append(flowIDs, label.GetValue())
+						}
+					}
+					if metric.GetCounter().GetValue() > loss {
+						loss = metric.GetCounter().GetValue()
+					}
+				}
+			}
+			require.ElementsMatch(t, tt.wantFlowIDs, flowIDs)
+			require.Equal(t, tt.wantLoss, loss)
+			// The dropped series are not accumulated over the collections.
+			for i := 0; i < 2; i++ {
+				require.Equal(t, tt.wantDropped, gatherValue(t, p, metricStreamSeriesDropped, map[string]string{labelInstanceName: "test"}))
+			}
+		})
+	}
+}
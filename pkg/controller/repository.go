@@ -10,7 +10,10 @@ import (
 	"net"
 	"os"
 	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -48,16 +51,59 @@ const (
 	RunStdErr = "run.stderr"
 	// RunStdOut redirected standard output of the bngblaster.
 	RunStdOut = "run.stdout"
+	// RunTimeseriesFilename counters recorded while the bngblaster is running.
+	RunTimeseriesFilename = "run_timeseries.jsonl"
+	// RunExitFilename file that contains the exit code of the last run.
+	RunExitFilename = "run.exit"
+	// RunResolvedConfigFilename configuration of one run with the library references resolved.
+	RunResolvedConfigFilename = "config_resolved.json"
+	// MetadataFilename owner, description and labels of the instance.
+	MetadataFilename = "metadata.json"
+
+	// LibraryPrefix references a library file in the configuration or the stream config,
+	// e.g. library:full-table.mrt.
+	LibraryPrefix = "library:"
+	// defaultLibraryFolder is the library folder relative to the config folder.
+	defaultLibraryFolder = ".library"
 )
 
+// runFiles are the configuration, the metadata and the files written by a run.
+var runFiles = map[string]bool{
+	ConfigFilename:        true,
+	MetadataFilename:      true,
+	runPidFilename:        true,
+	RunLogFilename:        true,
+	RunConfigFilename:     true,
+	RunReportFilename:     true,
+	RunPcapFilename:       true,
+	RunSockFilename:       true,
+	RunStdErr:             true,
+	RunStdOut:             true,
+	RunTimeseriesFilename: true,
+	RunExitFilename:       true,
+
+	RunResolvedConfigFilename: true,
+}
+
+// IsRunFile returns true for the configuration, the metadata and the files written
+// by a run, which are managed by the controller and not by file uploads.
+func IsRunFile(name string) bool {
+	return runFiles[name]
+}
+
 // make sure the DefaultRepository implements UseRepository.
 var _ Repository = &DefaultRepository{}
 
 // DefaultRepository is the default Repository implementation.
 type DefaultRepository struct {
-	executable   string
-	configFolder string
-	allow_upload bool
+	executable    string
+	configFolder  string
+	libraryFolder string
+	allow_upload  bool
+	metrics       *Metrics
+	// startMu serializes the start of instances, so that two instances using the
+	// same interface can not pass the conflict check at the same time.
+	startMu sync.Mutex
 }
 
 // NewDefaultRepository is a constructor function for Repository.
@@ -74,17 +120,25 @@ func NewDefaultRepository(opts ...DefaultRepositoryOption) *DefaultRepository {
 }
 
 // ConfigFolder implements Repository.
-func (r DefaultRepository) ConfigFolder() string {
+func (r *DefaultRepository) ConfigFolder() string {
 	return r.configFolder
 }
 
+// LibraryFolder implements Repository.
+func (r *DefaultRepository) LibraryFolder() string {
+	if r.libraryFolder == "" {
+		return path.Join(r.configFolder, defaultLibraryFolder)
+	}
+	return r.libraryFolder
+}
+
 // AllowUpload implements Repository.
-func (r DefaultRepository) AllowUpload() bool {
+func (r *DefaultRepository) AllowUpload() bool {
 	return r.allow_upload
 }
 
 // Executable implements Repository.
-func (r DefaultRepository) Executable() string {
+func (r *DefaultRepository) Executable() string {
 	return r.executable
 }
 
@@ -105,7 +159,30 @@ func (r *DefaultRepository) Create(name string, config []byte) error {
 	if err := r.cleanupRunFiles(name); err != nil {
 		return err
 	}
-	return nil
+	metadata, _ := r.metadata(name)
+	return r.writeMetadata(name, metadata)
+}
+
+// Import implements Repository.
+func (r *DefaultRepository) Import(name string, folder string) error {
+	// An empty name would replace the whole config folder.
+	if name == "" {
+		return ErrInvalidName
+	}
+	if r.Running(name) {
+		return ErrBlasterRunning
+	}
+	// The pid and the socket belong to the process that wrote them.
+	for _, file := range []string{runPidFilename, RunSockFilename} {
+		if err := os.Remove(path.Join(folder, file)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	target := path.Join(r.configFolder, name)
+	if err := os.RemoveAll(target); err != nil {
+		return err
+	}
+	return os.Rename(folder, target)
 }
 
 // Delete implements Repository.
@@ -129,6 +206,9 @@ func (r *DefaultRepository) cleanupRunFiles(name string) error {
 		path.Join(folder, RunSockFilename),
 		path.Join(folder, RunStdErr),
 		path.Join(folder, RunStdOut),
+		path.Join(folder, RunTimeseriesFilename),
+		path.Join(folder, RunExitFilename),
+		path.Join(folder, RunResolvedConfigFilename),
 	}
 	for _, f := range files {
 		if err := os.Remove(f); err != nil {
@@ -149,7 +229,8 @@ func (r *DefaultRepository) Instances() []string {
 		return instances // Return the empty slice if there's an error.
 	}
 	for _, entry := range entries {
-		if entry.IsDir() {
+		// Hidden folders are no instances, e.g. the library or an import in progress.
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
 			instances = append(instances, entry.Name())
 		}
 	}
@@ -190,14 +271,45 @@ func (r *DefaultRepository) Running(name string) bool {
 	return false
 }
 
+// Check implements Repository.
+func (r *DefaultRepository) Check(name string, runningConfig RunningConfig) error {
+	if !r.Exists(name) {
+		return ErrBlasterNotExists
+	}
+	if r.Running(name) {
+		return ErrBlasterRunning
+	}
+	if _, err := r.streamConfigFile(name, runningConfig.StreamConfig); err != nil {
+		return err
+	}
+	if _, err := r.resolvedConfig(name); err != nil {
+		return err
+	}
+	return r.interfaceConflict(name)
+}
+
 // Start implements Repository.
 func (r *DefaultRepository) Start(name string, runningConfig RunningConfig) error {
+	r.startMu.Lock()
+	defer r.startMu.Unlock()
 	if !r.Exists(name) {
 		return ErrBlasterNotExists
 	}
 	if r.Running(name) {
 		return ErrBlasterRunning
 	}
+	if err := r.interfaceConflict(name); err != nil {
+		return err
+	}
+	// Validate the request before the files of the last run are removed.
+	streamConfig, err := r.streamConfigFile(name, runningConfig.StreamConfig)
+	if err != nil {
+		return err
+	}
+	resolved, err := r.resolvedConfig(name)
+	if err != nil {
+		return err
+	}
 	if err := r.cleanupRunFiles(name); err != nil {
 		return err
 	}
@@ -210,13 +322,44 @@ func (r *DefaultRepository) Start(name string, runningConfig RunningConfig) erro
 	if err := os.WriteFile(file, config, permission); err != nil {
 		return err
 	}
+	runningConfig.StreamConfig = streamConfig
+	if err := r.writeResolvedConfig(name, resolved); err != nil {
+		return err
+	}
 	params := r.commandlineParameters(name, runningConfig)
-	_, err = RunCommand(
+	done, err := RunCommand(
 		path.Join(folder, runPidFilename),
 		path.Join(folder, RunStdOut),
 		path.Join(folder, RunStdErr),
 		params...)
-	return err
+	if err != nil {
+		r.metrics.StartFailed()
+		return err
+	}
+	stopped := make(chan struct{})
+	if runningConfig.TimeseriesInterval > 0 {
+		go r.recordTimeseries(name, runningConfig, stopped)
+	}
+	go func() {
+		code := <-done
+		r.metrics.Exited(code)
+		_ = os.WriteFile(path.Join(folder, RunExitFilename), []byte(strconv.Itoa(code)), permission)
+		close(stopped)
+	}()
+	return nil
+}
+
+// ReadExitCode returns the exit code of the last run stored in the instance folder.
+func ReadExitCode(folder string) (int, bool) {
+	data, err := os.ReadFile(path.Join(folder, RunExitFilename))
+	if err != nil {
+		return 0, false
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, false
+	}
+	return code, true
 }
 
 // Stop implements Repository.
@@ -250,7 +393,11 @@ func (r *DefaultRepository) commandlineParameters(name string, runningConfig Run
 	folder := path.Join(r.configFolder, name)
 	var params []string
 	params = append(params, r.executable)
-	params = append(params, "-C", path.Join(folder, ConfigFilename))
+	if _, err := os.Stat(path.Join(folder, RunResolvedConfigFilename)); err == nil {
+		params = append(params, "-C", path.Join(folder, RunResolvedConfigFilename))
+	} else {
+		params = append(params, "-C", path.Join(folder, ConfigFilename))
+	}
 	params = append(params, "-S", path.Join(folder, RunSockFilename))
 	if runningConfig.Report {
 		params = append(params, "-J", path.Join(folder, RunReportFilename))
@@ -279,6 +426,47 @@ func (r *DefaultRepository) commandlineParameters(name string, runningConfig Run
 	return params
 }
 
+// streamConfigFile returns the absolute path of the stream configuration file of
+// an instance. Relative paths are resolved against the instance folder, absolute
+// paths must be within the instance or library folder.
+func (r *DefaultRepository) streamConfigFile(name string, streamConfig string) (string, error) {
+	if streamConfig == "" {
+		return "", nil
+	}
+	if file, ok := r.libraryFile(streamConfig); ok {
+		return file, r.checkLibraryFile(streamConfig)
+	}
+	folder, err := filepath.Abs(path.Join(r.configFolder, name))
+	if err != nil {
+		return "", err
+	}
+	file := streamConfig
+	if !filepath.IsAbs(file) {
+		if !filepath.IsLocal(file) {
+			return "", fmt.Errorf("%w: %s is outside of the instance folder", ErrInvalidStreamConfig, streamConfig)
+		}
+		file = filepath.Join(folder, file)
+	}
+	// Resolve symbolic links, which could point outside of the allowed folders.
+	file, err = filepath.EvalSymlinks(file)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s does not exist", ErrInvalidStreamConfig, streamConfig)
+	}
+	if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%w: %s is no file", ErrInvalidStreamConfig, streamConfig)
+	}
+	for _, root := range []string{folder, r.libraryPath("")} {
+		root, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(root, file); err == nil && filepath.IsLocal(rel) {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s is outside of the instance and library folder", ErrInvalidStreamConfig, streamConfig)
+}
+
 func (r *DefaultRepository) config(name string) ([]byte, error) {
 	folder := path.Join(r.configFolder, name)
 	file := path.Join(folder, ConfigFilename)
@@ -293,6 +481,13 @@ func (r *DefaultRepository) Command(name string, command SocketCommand) ([]byte,
 	if !r.Running(name) {
 		return nil, ErrBlasterNotRunning
 	}
+	start := time.Now()
+	result, err := r.command(name, command)
+	r.metrics.ObserveCommand(command.Command, time.Since(start), err)
+	return result, err
+}
+
+func (r *DefaultRepository) command(name string, command SocketCommand) ([]byte, error) {
 	folder := path.Join(r.configFolder, name)
 	file := path.Join(folder, RunSockFilename)
 	// Open Socket
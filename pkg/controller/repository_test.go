@@ -4,6 +4,7 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -207,6 +208,55 @@ func TestDefaultRepository_commandlineParameters(t *testing.T) {
 	}
 }
 
+func TestDefaultRepository_streamConfigFile(t *testing.T) {
+	configFolder := t.TempDir()
+	library := t.TempDir()
+	outside := t.TempDir()
+	instance := path.Join(configFolder, "test")
+	require.NoError(t, os.MkdirAll(path.Join(instance, "streams"), 0o755))
+	for _, file := range []string{
+		path.Join(instance, "streams.json"),
+		path.Join(instance, "streams", "v6.json"),
+		path.Join(library, "shared.json"),
+		path.Join(outside, "secret.json"),
+	} {
+		require.NoError(t, os.WriteFile(file, []byte("{}"), permission))
+	}
+	require.NoError(t, os.Symlink(path.Join(outside, "secret.json"), path.Join(instance, "link.json")))
+	r := NewDefaultRepository(WithConfigFolder(configFolder), WithLibraryFolder(library))
+
+	tests := []struct {
+		name         string
+		streamConfig string
+		want         string
+		wantErr      error
+	}{
+		{name: "empty", streamConfig: "", want: ""},
+		{name: "relative", streamConfig: "streams.json", want: path.Join(instance, "streams.json")},
+		{name: "sub_folder", streamConfig: "streams/v6.json", want: path.Join(instance, "streams", "v6.json")},
+		{name: "absolute", streamConfig: path.Join(instance, "streams.json"), want: path.Join(instance, "streams.json")},
+		{name: "absolute_library", streamConfig: path.Join(library, "shared.json"), want: path.Join(library, "shared.json")},
+		{name: "library", streamConfig: "library:shared.json", want: path.Join(library, "shared.json")},
+		{name: "library_missing", streamConfig: "library:missing.json", wantErr: ErrLibraryFileNotExists},
+		{name: "missing", streamConfig: "missing.json", wantErr: ErrInvalidStreamConfig},
+		{name: "folder", streamConfig: "streams", wantErr: ErrInvalidStreamConfig},
+		{name: "traversal", streamConfig: "../test/streams.json", wantErr: ErrInvalidStreamConfig},
+		{name: "absolute_outside", streamConfig: path.Join(outside, "secret.json"), wantErr: ErrInvalidStreamConfig},
+		{name: "symlink_outside", streamConfig: "link.json", wantErr: ErrInvalidStreamConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.streamConfigFile("test", tt.streamConfig)
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr), "error %v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestDefaultRepository_Start(t *testing.T) {
 	defaultExecCommand := ExecCommand
 	ExecCommand = fakeExecCommand
@@ -255,6 +305,45 @@ func TestDefaultRepository_Start(t *testing.T) {
 	}
 }
 
+func TestDefaultRepository_StartInvalid(t *testing.T) {
+	configFolder := t.TempDir()
+	r := NewDefaultRepository(WithConfigFolder(configFolder), WithLibraryFolder(t.TempDir()), WithExecutable("test"))
+
+	tests := []struct {
+		name          string
+		config        string
+		runningConfig RunningConfig
+		wantErr       error
+	}{
+		{
+			name:          "invalid_stream_config",
+			config:        `{}`,
+			runningConfig: RunningConfig{StreamConfig: "../streams.json"},
+			wantErr:       ErrInvalidStreamConfig,
+		}, {
+			name:    "missing_library_file",
+			config:  `{"bgp": [{"raw-update-file": "library:missing.mrt"}]}`,
+			wantErr: ErrLibraryFileNotExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, r.Create(tt.name, []byte(tt.config)))
+			folder := path.Join(configFolder, tt.name)
+			lastRun := []byte(`{"report": true}`)
+			require.NoError(t, os.WriteFile(path.Join(folder, RunConfigFilename), lastRun, permission))
+			require.NoError(t, os.WriteFile(path.Join(folder, RunReportFilename), []byte("{}"), permission))
+
+			err := r.Start(tt.name, tt.runningConfig)
+			require.True(t, errors.Is(err, tt.wantErr), "error %v", err)
+
+			// The files of the last run are unchanged.
+			require.Equal(t, lastRun, mustRead(t, path.Join(folder, RunConfigFilename)))
+			require.Equal(t, []byte("{}"), mustRead(t, path.Join(folder, RunReportFilename)))
+		})
+	}
+}
+
 func TestDefaultRepository_Delete(t *testing.T) {
 	const rootFolder = "td"
 	writePidFileForRunning(t, rootFolder)
@@ -301,6 +390,52 @@ func TestDefaultRepository_Delete(t *testing.T) {
 	}
 }
 
+func TestDefaultRepository_Import(t *testing.T) {
+	const rootFolder = "td"
+	writePidFileForRunning(t, rootFolder)
+	defer cleanupPidFileForRunning(t, rootFolder)
+	defer os.RemoveAll(path.Join(rootFolder, "imported"))
+
+	r := NewDefaultRepository(WithConfigFolder(rootFolder), WithExecutable("test"))
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{
+			name:    "imported",
+			wantErr: false,
+		}, {
+			name:    "running",
+			wantErr: true,
+		}, {
+			name:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder, err := os.MkdirTemp(rootFolder, ".import-")
+			require.NoError(t, err)
+			defer os.RemoveAll(folder)
+			require.NoError(t, os.WriteFile(path.Join(folder, ConfigFilename), []byte("{}"), permission))
+			require.NoError(t, os.WriteFile(path.Join(folder, runPidFilename), []byte("1"), permission))
+			require.NotContains(t, r.Instances(), path.Base(folder))
+
+			if err := r.Import(tt.name, folder); (err != nil) != tt.wantErr {
+				t.Fatalf("Import() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			require.Equal(t, []byte("{}"), mustRead(t, path.Join(rootFolder, tt.name, ConfigFilename)))
+			_, err = os.Stat(path.Join(rootFolder, tt.name, runPidFilename))
+			require.True(t, os.IsNotExist(err))
+			_, err = os.Stat(folder)
+			require.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
 func TestDefaultRepository_Command(t *testing.T) {
 	const rootFolder = "td"
 	writePidFileForRunning(t, rootFolder)
@@ -20,6 +20,9 @@ var _ Repository = &RepositoryMock{}
 //			AllowUploadFunc: func() bool {
 //				panic("mock out the AllowUpload method")
 //			},
+//			CheckFunc: func(name string, runningConfig RunningConfig) error {
+//				panic("mock out the Check method")
+//			},
 //			CommandFunc: func(name string, command SocketCommand) ([]byte, error) {
 //				panic("mock out the Command method")
 //			},
@@ -38,15 +41,27 @@ var _ Repository = &RepositoryMock{}
 //			ExistsFunc: func(name string) bool {
 //				panic("mock out the Exists method")
 //			},
+//			ImportFunc: func(name string, folder string) error {
+//				panic("mock out the Import method")
+//			},
+//			InfoFunc: func(name string) (InstanceInfo, error) {
+//				panic("mock out the Info method")
+//			},
 //			InstancesFunc: func() []string {
 //				panic("mock out the Instances method")
 //			},
 //			KillFunc: func(name string)  {
 //				panic("mock out the Kill method")
 //			},
+//			LibraryFolderFunc: func() string {
+//				panic("mock out the LibraryFolder method")
+//			},
 //			RunningFunc: func(name string) bool {
 //				panic("mock out the Running method")
 //			},
+//			SetMetadataFunc: func(name string, metadata Metadata) error {
+//				panic("mock out the SetMetadata method")
+//			},
 //			StartFunc: func(name string, runningConfig RunningConfig) error {
 //				panic("mock out the Start method")
 //			},
@@ -63,6 +78,9 @@ type RepositoryMock struct {
 	// AllowUploadFunc mocks the AllowUpload method.
 	AllowUploadFunc func() bool
 
+	// CheckFunc mocks the Check method.
+	CheckFunc func(name string, runningConfig RunningConfig) error
+
 	// CommandFunc mocks the Command method.
 	CommandFunc func(name string, command SocketCommand) ([]byte, error)
 
@@ -81,15 +99,27 @@ type RepositoryMock struct {
 	// ExistsFunc mocks the Exists method.
 	ExistsFunc func(name string) bool
 
+	// ImportFunc mocks the Import method.
+	ImportFunc func(name string, folder string) error
+
+	// InfoFunc mocks the Info method.
+	InfoFunc func(name string) (InstanceInfo, error)
+
 	// InstancesFunc mocks the Instances method.
 	InstancesFunc func() []string
 
 	// KillFunc mocks the Kill method.
 	KillFunc func(name string)
 
+	// LibraryFolderFunc mocks the LibraryFolder method.
+	LibraryFolderFunc func() string
+
 	// RunningFunc mocks the Running method.
 	RunningFunc func(name string) bool
 
+	// SetMetadataFunc mocks the SetMetadata method.
+	SetMetadataFunc func(name string, metadata Metadata) error
+
 	// StartFunc mocks the Start method.
 	StartFunc func(name string, runningConfig RunningConfig) error
 
@@ -101,6 +131,13 @@ type RepositoryMock struct {
 		// AllowUpload holds details about calls to the AllowUpload method.
 		AllowUpload []struct {
 		}
+		// Check holds details about calls to the Check method.
+		Check []struct {
+			// Name is the name argument value.
+			Name string
+			// RunningConfig is the runningConfig argument value.
+			RunningConfig RunningConfig
+		}
 		// Command holds details about calls to the Command method.
 		Command []struct {
 			// Name is the name argument value.
@@ -131,6 +168,18 @@ type RepositoryMock struct {
 			// Name is the name argument value.
 			Name string
 		}
+		// Import holds details about calls to the Import method.
+		Import []struct {
+			// Name is the name argument value.
+			Name string
+			// Folder is the folder argument value.
+			Folder string
+		}
+		// Info holds details about calls to the Info method.
+		Info []struct {
+			// Name is the name argument value.
+			Name string
+		}
 		// Instances holds details about calls to the Instances method.
 		Instances []struct {
 		}
@@ -139,11 +188,21 @@ type RepositoryMock struct {
 			// Name is the name argument value.
 			Name string
 		}
+		// LibraryFolder holds details about calls to the LibraryFolder method.
+		LibraryFolder []struct {
+		}
 		// Running holds details about calls to the Running method.
 		Running []struct {
 			// Name is the name argument value.
 			Name string
 		}
+		// SetMetadata holds details about calls to the SetMetadata method.
+		SetMetadata []struct {
+			// Name is the name argument value.
+			Name string
+			// Metadata is the metadata argument value.
+			Metadata Metadata
+		}
 		// Start holds details about calls to the Start method.
 		Start []struct {
 			// Name is the name argument value.
@@ -157,18 +216,23 @@ type RepositoryMock struct {
 			Name string
 		}
 	}
-	lockAllowUpload  sync.RWMutex
-	lockCommand      sync.RWMutex
-	lockConfigFolder sync.RWMutex
-	lockCreate       sync.RWMutex
-	lockDelete       sync.RWMutex
-	lockExecutable   sync.RWMutex
-	lockExists       sync.RWMutex
-	lockInstances    sync.RWMutex
-	lockKill         sync.RWMutex
-	lockRunning      sync.RWMutex
-	lockStart        sync.RWMutex
-	lockStop         sync.RWMutex
+	lockAllowUpload   sync.RWMutex
+	lockCheck         sync.RWMutex
+	lockCommand       sync.RWMutex
+	lockConfigFolder  sync.RWMutex
+	lockCreate        sync.RWMutex
+	lockDelete        sync.RWMutex
+	lockExecutable    sync.RWMutex
+	lockExists        sync.RWMutex
+	lockImport        sync.RWMutex
+	lockInfo          sync.RWMutex
+	lockInstances     sync.RWMutex
+	lockKill          sync.RWMutex
+	lockLibraryFolder sync.RWMutex
+	lockRunning       sync.RWMutex
+	lockSetMetadata   sync.RWMutex
+	lockStart         sync.RWMutex
+	lockStop          sync.RWMutex
 }
 
 // AllowUpload calls AllowUploadFunc.
@@ -198,6 +262,42 @@ func (mock *RepositoryMock) AllowUploadCalls() []struct {
 	return calls
 }
 
+// Check calls CheckFunc.
+func (mock *RepositoryMock) Check(name string, runningConfig RunningConfig) error {
+	if mock.CheckFunc == nil {
+		panic("RepositoryMock.CheckFunc: method is nil but Repository.Check was just called")
+	}
+	callInfo := struct {
+		Name          string
+		RunningConfig RunningConfig
+	}{
+		Name:          name,
+		RunningConfig: runningConfig,
+	}
+	mock.lockCheck.Lock()
+	mock.calls.Check = append(mock.calls.Check, callInfo)
+	mock.lockCheck.Unlock()
+	return mock.CheckFunc(name, runningConfig)
+}
+
+// CheckCalls gets all the calls that were made to Check.
+// Check the length with:
+//
+//	len(mockedRepository.CheckCalls())
+func (mock *RepositoryMock) CheckCalls() []struct {
+	Name          string
+	RunningConfig RunningConfig
+} {
+	var calls []struct {
+		Name          string
+		RunningConfig RunningConfig
+	}
+	mock.lockCheck.RLock()
+	calls = mock.calls.Check
+	mock.lockCheck.RUnlock()
+	return calls
+}
+
 // Command calls CommandFunc.
 func (mock *RepositoryMock) Command(name string, command SocketCommand) ([]byte, error) {
 	if mock.CommandFunc == nil {
@@ -388,6 +488,74 @@ func (mock *RepositoryMock) ExistsCalls() []struct {
 	return calls
 }
 
+// Import calls ImportFunc.
+func (mock *RepositoryMock) Import(name string, folder string) error {
+	if mock.ImportFunc == nil {
+		panic("RepositoryMock.ImportFunc: method is nil but Repository.Import was just called")
+	}
+	callInfo := struct {
+		Name   string
+		Folder string
+	}{
+		Name:   name,
+		Folder: folder,
+	}
+	mock.lockImport.Lock()
+	mock.calls.Import = append(mock.calls.Import, callInfo)
+	mock.lockImport.Unlock()
+	return mock.ImportFunc(name, folder)
+}
+
+// ImportCalls gets all the calls that were made to Import.
+// Check the length with:
+//
+//	len(mockedRepository.ImportCalls())
+func (mock *RepositoryMock) ImportCalls() []struct {
+	Name   string
+	Folder string
+} {
+	var calls []struct {
+		Name   string
+		Folder string
+	}
+	mock.lockImport.RLock()
+	calls = mock.calls.Import
+	mock.lockImport.RUnlock()
+	return calls
+}
+
+// Info calls InfoFunc.
+func (mock *RepositoryMock) Info(name string) (InstanceInfo, error) {
+	if mock.InfoFunc == nil {
+		panic("RepositoryMock.InfoFunc: method is nil but Repository.Info was just called")
+	}
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	mock.lockInfo.Lock()
+	mock.calls.Info = append(mock.calls.Info, callInfo)
+	mock.lockInfo.Unlock()
+	return mock.InfoFunc(name)
+}
+
+// InfoCalls gets all the calls that were made to Info.
+// Check the length with:
+//
+//	len(mockedRepository.InfoCalls())
+func (mock *RepositoryMock) InfoCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	mock.lockInfo.RLock()
+	calls = mock.calls.Info
+	mock.lockInfo.RUnlock()
+	return calls
+}
+
 // Instances calls InstancesFunc.
 func (mock *RepositoryMock) Instances() []string {
 	if mock.InstancesFunc == nil {
@@ -447,6 +615,33 @@ func (mock *RepositoryMock) KillCalls() []struct {
 	return calls
 }
 
+// LibraryFolder calls LibraryFolderFunc.
+func (mock *RepositoryMock) LibraryFolder() string {
+	if mock.LibraryFolderFunc == nil {
+		panic("RepositoryMock.LibraryFolderFunc: method is nil but Repository.LibraryFolder was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockLibraryFolder.Lock()
+	mock.calls.LibraryFolder = append(mock.calls.LibraryFolder, callInfo)
+	mock.lockLibraryFolder.Unlock()
+	return mock.LibraryFolderFunc()
+}
+
+// LibraryFolderCalls gets all the calls that were made to LibraryFolder.
+// Check the length with:
+//
+//	len(mockedRepository.LibraryFolderCalls())
+func (mock *RepositoryMock) LibraryFolderCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockLibraryFolder.RLock()
+	calls = mock.calls.LibraryFolder
+	mock.lockLibraryFolder.RUnlock()
+	return calls
+}
+
 // Running calls RunningFunc.
 func (mock *RepositoryMock) Running(name string) bool {
 	if mock.RunningFunc == nil {
@@ -479,6 +674,42 @@ func (mock *RepositoryMock) RunningCalls() []struct {
 	return calls
 }
 
+// SetMetadata calls SetMetadataFunc.
+func (mock *RepositoryMock) SetMetadata(name string, metadata Metadata) error {
+	if mock.SetMetadataFunc == nil {
+		panic("RepositoryMock.SetMetadataFunc: method is nil but Repository.SetMetadata was just called")
+	}
+	callInfo := struct {
+		Name     string
+		Metadata Metadata
+	}{
+		Name:     name,
+		Metadata: metadata,
+	}
+	mock.lockSetMetadata.Lock()
+	mock.calls.SetMetadata = append(mock.calls.SetMetadata, callInfo)
+	mock.lockSetMetadata.Unlock()
+	return mock.SetMetadataFunc(name, metadata)
+}
+
+// SetMetadataCalls gets all the calls that were made to SetMetadata.
+// Check the length with:
+//
+//	len(mockedRepository.SetMetadataCalls())
+func (mock *RepositoryMock) SetMetadataCalls() []struct {
+	Name     string
+	Metadata Metadata
+} {
+	var calls []struct {
+		Name     string
+		Metadata Metadata
+	}
+	mock.lockSetMetadata.RLock()
+	calls = mock.calls.SetMetadata
+	mock.lockSetMetadata.RUnlock()
+	return calls
+}
+
 // Start calls StartFunc.
 func (mock *RepositoryMock) Start(name string, runningConfig RunningConfig) error {
 	if mock.StartFunc == nil {
@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// defaultTimeseriesFlags are the commands sampled if no timeseries flags are specified.
+var defaultTimeseriesFlags = []string{"session_counters", "interfaces"}
+
+// timeseriesCommands maps the timeseries flags to the sampled socket commands.
+var timeseriesCommands = map[string]string{
+	"session_counters":   "session-counters",
+	"interfaces":         "interfaces",
+	"access_interfaces":  "access-interfaces",
+	"network_interfaces": "network-interfaces",
+	"a10nsp_interfaces":  "a10nsp-interfaces",
+	"streams":            "stream-summary",
+}
+
+// TimeseriesSample is one line of the timeseries file.
+// The values are the numeric fields of the sampled commands, named
+// <command>.<field> for objects (e.g. session-counters.setup-rate) and
+// <command>.<name>.<field> for lists (e.g. interfaces.eth1.rx-pps or
+// stream-summary.1.rx-loss for the stream with flow id 1).
+type TimeseriesSample struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Values    map[string]float64 `json:"values"`
+}
+
+// TimeseriesPoint is one value of a timeseries.
+type TimeseriesPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// Timeseries are the recorded values of one metric.
+type Timeseries struct {
+	Metric string            `json:"metric"`
+	Points []TimeseriesPoint `json:"points"`
+}
+
+// ValidateTimeseries checks the timeseries options of the running configuration.
+func ValidateTimeseries(runningConfig RunningConfig) error {
+	if runningConfig.TimeseriesInterval < 0 {
+		return fmt.Errorf("timeseries_interval must not be negative")
+	}
+	for _, flag := range runningConfig.TimeseriesFlags {
+		if _, ok := timeseriesCommands[flag]; !ok {
+			return fmt.Errorf("invalid timeseries flag %q", flag)
+		}
+	}
+	return nil
+}
+
+// recordTimeseries samples the configured commands of a running instance
+// until done is closed and appends the samples to the timeseries file.
+func (r *DefaultRepository) recordTimeseries(name string, runningConfig RunningConfig, done <-chan struct{}) {
+	file, err := os.OpenFile(path.Join(r.configFolder, name, RunTimeseriesFilename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, permission)
+	if err != nil {
+		log.Warn().Msgf("failed to create timeseries of %s: %s", name, err.Error())
+		return
+	}
+	defer file.Close()
+
+	flags := runningConfig.TimeseriesFlags
+	if len(flags) == 0 {
+		flags = defaultTimeseriesFlags
+	}
+	ticker := time.NewTicker(time.Duration(runningConfig.TimeseriesInterval) * time.Second)
+	defer ticker.Stop()
+	encoder := json.NewEncoder(file)
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			sample := TimeseriesSample{Timestamp: now.UTC(), Values: map[string]float64{}}
+			for _, flag := range flags {
+				command := timeseriesCommands[flag]
+				result, err := r.Command(name, SocketCommand{Command: command})
+				if err != nil {
+					// The control socket is not available before the
+					// bngblaster is initialised and after it stopped.
+					log.Debug().Msgf("failed to sample %s of %s: %s", command, name, err.Error())
+					continue
+				}
+				var cr map[string]interface{}
+				if err := json.Unmarshal(result, &cr); err != nil {
+					log.Debug().Msgf("failed to decode %s of %s: %s", command, name, err.Error())
+					continue
+				}
+				flattenSample(sample.Values, command, cr[command])
+			}
+			if len(sample.Values) == 0 {
+				continue
+			}
+			if err := encoder.Encode(sample); err != nil {
+				log.Warn().Msgf("failed to write timeseries of %s: %s", name, err.Error())
+				return
+			}
+		}
+	}
+}
+
+// flattenSample adds all numeric fields of v to the values with the given prefix.
+// List entries are named by their flow id or name, the index otherwise.
+func flattenSample(values map[string]float64, prefix string, v interface{}) {
+	switch v := v.(type) {
+	case float64:
+		values[prefix] = v
+	case map[string]interface{}:
+		for key, value := range v {
+			flattenSample(values, prefix+"."+key, value)
+		}
+	case []interface{}:
+		for i, item := range v {
+			key := fmt.Sprint(i)
+			if object, ok := item.(map[string]interface{}); ok {
+				if id, ok := object["flow-id"].(float64); ok {
+					key = fmt.Sprint(id)
+				} else if name, ok := object["name"].(string); ok {
+					key = name
+				}
+			}
+			flattenSample(values, prefix+"."+key, item)
+		}
+	}
+}
+
+// ReadTimeseries reads the timeseries file and returns the series of all metrics
+// matching one of the patterns (see path.Match), or all metrics if no pattern is given.
+// The error wraps path.ErrBadPattern if a pattern is invalid.
+func ReadTimeseries(file string, patterns []string) ([]Timeseries, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid metric pattern %q: %w", pattern, err)
+		}
+	}
+	match := func(metric string) bool {
+		if len(patterns) == 0 {
+			return true
+		}
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, metric); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	series := map[string]*Timeseries{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var sample TimeseriesSample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			// The last line might be incomplete while the instance is running.
+			continue
+		}
+		for metric, value := range sample.Values {
+			if !match(metric) {
+				continue
+			}
+			s, ok := series[metric]
+			if !ok {
+				s = &Timeseries{Metric: metric}
+				series[metric] = s
+			}
+			s.Points = append(s.Points, TimeseriesPoint{Timestamp: sample.Timestamp, Value: value})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]Timeseries, 0, len(series))
+	for _, s := range series {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Metric < result[j].Metric
+	})
+	return result, nil
+}
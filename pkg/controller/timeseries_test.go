@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package controller
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenSample(t *testing.T) {
+	var cr map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(promCommandResults["session-counters"]), &cr))
+	values := map[string]float64{}
+	flattenSample(values, "session-counters", cr["session-counters"])
+	require.Equal(t, map[string]float64{
+		"session-counters.sessions":             10,
+		"session-counters.sessions-established": 8,
+	}, values)
+
+	require.NoError(t, json.Unmarshal([]byte(promCommandResults["stream-summary"]), &cr))
+	values = map[string]float64{}
+	flattenSample(values, "stream-summary", cr["stream-summary"])
+	require.Equal(t, float64(5), values["stream-summary.1.rx-loss"])
+	require.Equal(t, float64(1), values["stream-summary.2.session-id"])
+	require.NotContains(t, values, "stream-summary.1.name")
+}
+
+func TestReadTimeseries(t *testing.T) {
+	file := path.Join(t.TempDir(), RunTimeseriesFilename)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	encoder := json.NewEncoder(f)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, encoder.Encode(TimeseriesSample{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Values: map[string]float64{
+				"session-counters.setup-rate": float64(i * 100),
+				"interfaces.eth1.rx-pps":      float64(i),
+				"interfaces.eth2.rx-pps":      float64(i * 2),
+			},
+		}))
+	}
+	// Incomplete line of a running instance.
+	_, _ = f.WriteString(`{"timestamp": "2025-01-01T00:00:03Z", "val`)
+	require.NoError(t, f.Close())
+
+	tests := []struct {
+		name        string
+		patterns    []string
+		wantMetrics []string
+		wantErr     bool
+	}{
+		{
+			name:        "all",
+			wantMetrics: []string{"interfaces.eth1.rx-pps", "interfaces.eth2.rx-pps", "session-counters.setup-rate"},
+		}, {
+			name:        "metric",
+			patterns:    []string{"session-counters.setup-rate"},
+			wantMetrics: []string{"session-counters.setup-rate"},
+		}, {
+			name:        "pattern",
+			patterns:    []string{"interfaces.*.rx-pps"},
+			wantMetrics: []string{"interfaces.eth1.rx-pps", "interfaces.eth2.rx-pps"},
+		}, {
+			name:     "invalid_pattern",
+			patterns: []string{"["},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := ReadTimeseries(file, tt.patterns)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var metrics []string
+			for _, s := range series {
+				metrics = append(metrics, s.Metric)
+				require.Len(t, s.Points, 3)
+				require.Equal(t, start, s.Points[0].Timestamp)
+			}
+			require.Equal(t, tt.wantMetrics, metrics)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+
+//go:build linux
+
+package netlink
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// ethtoolFamily is the name of the ethtool generic netlink family.
+	ethtoolFamily = "ethtool"
+	// genlVersion is the version of the generic netlink controller and ethtool.
+	genlVersion = 1
+)
+
+// sequence numbers the requests.
+var sequence atomic.Uint32
+
+// request sends a netlink request and returns the response messages,
+// the request is acknowledged or fails with the error of the kernel.
+func request(protocol int, typ uint16, payload []byte) ([]syscall.NetlinkMessage, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, protocol)
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	seq := sequence.Add(1)
+	b := make([]byte, unix.SizeofNlMsghdr, unix.SizeofNlMsghdr+len(payload))
+	binary.NativeEndian.PutUint32(b[0:4], uint32(unix.SizeofNlMsghdr+len(payload)))
+	binary.NativeEndian.PutUint16(b[4:6], typ)
+	binary.NativeEndian.PutUint16(b[6:8], unix.NLM_F_REQUEST|unix.NLM_F_ACK)
+	binary.NativeEndian.PutUint32(b[8:12], seq)
+	b = append(b, payload...)
+	if err := unix.Sendto(fd, b, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	var messages []syscall.NetlinkMessage
+	buf := make([]byte, 1<<16)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, err
+		}
+		// The messages reference the buffer, which is reused.
+		received, err := syscall.ParseNetlinkMessage(append([]byte(nil), buf[:n]...))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range received {
+			if m.Header.Seq != seq {
+				continue
+			}
+			if m.Header.Type != unix.NLMSG_ERROR {
+				messages = append(messages, m)
+				continue
+			}
+			if len(m.Data) < 4 {
+				return nil, fmt.Errorf("netlink: short error message")
+			}
+			// The error code is 0 for the acknowledgement.
+			if code := int32(binary.NativeEndian.Uint32(m.Data[0:4])); code != 0 {
+				return nil, syscall.Errno(-code)
+			}
+			return messages, nil
+		}
+	}
+}
+
+// setLink changes the flags and attributes of an interface.
+func setLink(name string, flags uint32, change uint32, attrs ...[]byte) error {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return err
+	}
+	// struct ifinfomsg
+	b := make([]byte, unix.SizeofIfInfomsg)
+	b[0] = unix.AF_UNSPEC
+	binary.NativeEndian.PutUint32(b[4:8], uint32(iface.Index))
+	binary.NativeEndian.PutUint32(b[8:12], flags)
+	binary.NativeEndian.PutUint32(b[12:16], change)
+	for _, a := range attrs {
+		b = append(b, a...)
+	}
+	_, err = request(unix.NETLINK_ROUTE, unix.RTM_NEWLINK, b)
+	return err
+}
+
+// SetLinkUp sets the interface administratively up or down.
+func SetLinkUp(name string, up bool) error {
+	var flags uint32
+	if up {
+		flags = unix.IFF_UP
+	}
+	return setLink(name, flags, unix.IFF_UP)
+}
+
+// SetLinkMTU sets the MTU of the interface.
+func SetLinkMTU(name string, mtu int) error {
+	return setLink(name, 0, 0, uint32Attr(unix.IFLA_MTU, uint32(mtu)))
+}
+
+// SetLinkPromisc enables or disables the promiscuous mode of the interface.
+func SetLinkPromisc(name string, promisc bool) error {
+	var flags uint32
+	if promisc {
+		flags = unix.IFF_PROMISC
+	}
+	return setLink(name, flags, unix.IFF_PROMISC)
+}
+
+// genlHeader returns the generic netlink header of a command.
+func genlHeader(cmd uint8) []byte {
+	return []byte{cmd, genlVersion, 0, 0}
+}
+
+// familyID resolves the id of a generic netlink family.
+func familyID(name string) (uint16, error) {
+	payload := append(genlHeader(unix.CTRL_CMD_GETFAMILY), stringAttr(unix.CTRL_ATTR_FAMILY_NAME, name)...)
+	messages, err := request(unix.NETLINK_GENERIC, unix.GENL_ID_CTRL, payload)
+	if err != nil {
+		return 0, fmt.Errorf("netlink family %s: %w", name, err)
+	}
+	for _, m := range messages {
+		if len(m.Data) < 4 {
+			continue
+		}
+		if id, ok := parseAttrs(m.Data[4:])[unix.CTRL_ATTR_FAMILY_ID]; ok && len(id) >= 2 {
+			return binary.NativeEndian.Uint16(id), nil
+		}
+	}
+	return 0, fmt.Errorf("netlink family %s not found", name)
+}
+
+// SetRingSizes sets the sizes of the rx and tx rings of the interface,
+// a size of zero keeps the current size.
+func SetRingSizes(name string, rx uint32, tx uint32) error {
+	family, err := familyID(ethtoolFamily)
+	if err != nil {
+		return err
+	}
+	payload := genlHeader(unix.ETHTOOL_MSG_RINGS_SET)
+	payload = append(payload, attr(unix.ETHTOOL_A_RINGS_HEADER|unix.NLA_F_NESTED,
+		stringAttr(unix.ETHTOOL_A_HEADER_DEV_NAME, name))...)
+	if rx > 0 {
+		payload = append(payload, uint32Attr(unix.ETHTOOL_A_RINGS_RX, rx)...)
+	}
+	if tx > 0 {
+		payload = append(payload, uint32Attr(unix.ETHTOOL_A_RINGS_TX, tx)...)
+	}
+	_, err = request(unix.NETLINK_GENERIC, family, payload)
+	return err
+}
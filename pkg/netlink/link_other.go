@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+
+//go:build !linux
+
+package netlink
+
+// SetLinkUp sets the interface administratively up or down.
+func SetLinkUp(name string, up bool) error {
+	return ErrNotSupported
+}
+
+// SetLinkMTU sets the MTU of the interface.
+func SetLinkMTU(name string, mtu int) error {
+	return ErrNotSupported
+}
+
+// SetLinkPromisc enables or disables the promiscuous mode of the interface.
+func SetLinkPromisc(name string, promisc bool) error {
+	return ErrNotSupported
+}
+
+// SetRingSizes sets the sizes of the rx and tx rings of the interface.
+func SetRingSizes(name string, rx uint32, tx uint32) error {
+	return ErrNotSupported
+}
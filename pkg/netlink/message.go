@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+
+// Package netlink changes the settings of network interfaces with rtnetlink
+// and the ethtool generic netlink family.
+package netlink
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrNotSupported is returned on platforms without netlink.
+var ErrNotSupported = errors.New("netlink is not supported on this platform")
+
+// align returns the length aligned to 4 bytes.
+func align(length int) int {
+	return (length + 3) &^ 3
+}
+
+// attr encodes a netlink attribute.
+func attr(typ uint16, data []byte) []byte {
+	length := 4 + len(data)
+	b := make([]byte, align(length))
+	binary.NativeEndian.PutUint16(b[0:2], uint16(length))
+	binary.NativeEndian.PutUint16(b[2:4], typ)
+	copy(b[4:], data)
+	return b
+}
+
+// uint32Attr encodes a netlink attribute with a 32 bit value.
+func uint32Attr(typ uint16, value uint32) []byte {
+	b := make([]byte, 4)
+	binary.NativeEndian.PutUint32(b, value)
+	return attr(typ, b)
+}
+
+// stringAttr encodes a netlink attribute with a null terminated string.
+func stringAttr(typ uint16, value string) []byte {
+	return attr(typ, append([]byte(value), 0))
+}
+
+// parseAttrs returns the netlink attributes by type, the nested flag is removed.
+func parseAttrs(b []byte) map[uint16][]byte {
+	attrs := map[uint16][]byte{}
+	for len(b) >= 4 {
+		length := int(binary.NativeEndian.Uint16(b[0:2]))
+		typ := binary.NativeEndian.Uint16(b[2:4]) & 0x3fff
+		if length < 4 || length > len(b) {
+			break
+		}
+		attrs[typ] = b[4:length]
+		if align(length) >= len(b) {
+			break
+		}
+		b = b[align(length):]
+	}
+	return attrs
+}
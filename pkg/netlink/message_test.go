@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package netlink
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttr(t *testing.T) {
+	tests := []struct {
+		name       string
+		attr       []byte
+		wantLength int
+		wantSize   int
+	}{
+		{name: "uint32", attr: uint32Attr(4, 9000), wantLength: 8, wantSize: 8},
+		{name: "string", attr: stringAttr(2, "eth1"), wantLength: 9, wantSize: 12},
+		{name: "empty", attr: attr(1, nil), wantLength: 4, wantSize: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Len(t, tt.attr, tt.wantSize)
+			require.Equal(t, tt.wantLength, int(binary.NativeEndian.Uint16(tt.attr[0:2])))
+		})
+	}
+}
+
+func TestParseAttrs(t *testing.T) {
+	var b []byte
+	b = append(b, stringAttr(2, "ethtool")...)
+	b = append(b, attr(1|0x8000, stringAttr(2, "eth1"))...)
+	b = append(b, uint32Attr(6, 4096)...)
+
+	attrs := parseAttrs(b)
+	require.Len(t, attrs, 3)
+	require.Equal(t, "ethtool\x00", string(attrs[2]))
+	require.Equal(t, uint32(4096), binary.NativeEndian.Uint32(attrs[6]))
+	nested := parseAttrs(attrs[1])
+	require.Equal(t, "eth1\x00", string(nested[2]))
+
+	// Truncated attributes are ignored.
+	require.Len(t, parseAttrs(b[:len(b)-6]), 2)
+	require.Empty(t, parseAttrs([]byte{1, 2}))
+}
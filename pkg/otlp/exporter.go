@@ -0,0 +1,301 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultInterval is the default push interval.
+	DefaultInterval = 30 * time.Second
+	// metricsPath is the default OTLP/HTTP metrics path.
+	metricsPath = "/v1/metrics"
+
+	scopeName = "github.com/rtbrick/bngblaster-controller"
+
+	// Resource attributes.
+	attributeServiceName  = "service.name"
+	attributeHostName     = "host.name"
+	attributeInstanceName = "bngblaster.instance.name"
+	serviceName           = "bngblasterctrl"
+
+	// Prometheus labels that are exported as resource attributes.
+	labelHostname     = "hostname"
+	labelInstanceName = "instance_name"
+)
+
+// Exporter pushes the metrics of a prometheus gatherer with the
+// OTLP/HTTP JSON protocol to an OpenTelemetry collector.
+// The metrics of every bngblaster instance are exported as own resource.
+type Exporter struct {
+	gatherer prometheus.Gatherer
+	endpoint string
+	interval time.Duration
+	headers  map[string]string
+	client   *http.Client
+	hostname string
+	start    time.Time
+	done     chan struct{}
+}
+
+// NewExporter is a constructor function for Exporter.
+// The endpoint is the URL of the collector, the path defaults to /v1/metrics.
+func NewExporter(gatherer prometheus.Gatherer, endpoint string, opts ...Option) (*Exporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid otlp endpoint %q", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = metricsPath
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	e := &Exporter{
+		gatherer: gatherer,
+		endpoint: u.String(),
+		interval: DefaultInterval,
+		headers:  map[string]string{},
+		client:   &http.Client{Timeout: 10 * time.Second},
+		hostname: hostname,
+		start:    time.Now(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// Start pushing the metrics in the background.
+func (e *Exporter) Start() {
+	if e.done != nil {
+		return
+	}
+	e.done = make(chan struct{})
+	go func(done chan struct{}) {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := e.Push(context.Background()); err != nil {
+					log.Warn().Msgf("failed to push otlp metrics: %s", err.Error())
+				}
+			}
+		}
+	}(e.done)
+}
+
+// Stop pushing the metrics.
+func (e *Exporter) Stop() {
+	if e.done != nil {
+		close(e.done)
+		e.done = nil
+	}
+}
+
+// Push gathers the metrics and pushes them once.
+func (e *Exporter) Push(ctx context.Context) error {
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		// Gather returns as many metrics as possible together with the error.
+		log.Warn().Msgf("failed to gather metrics: %s", err.Error())
+	}
+	body, err := json.Marshal(e.request(families, time.Now()))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("otlp collector responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// request converts the metric families into an OTLP request with one
+// resource for the controller and one for every bngblaster instance.
+func (e *Exporter) request(families []*dto.MetricFamily, now time.Time) *ExportMetricsServiceRequest {
+	timestamp := strconv.FormatInt(now.UnixNano(), 10)
+	start := strconv.FormatInt(e.start.UnixNano(), 10)
+
+	// Metrics per instance name ("" is the controller) and metric name.
+	resources := map[string]map[string]*Metric{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			instance := ""
+			var attributes []KeyValue
+			for _, label := range m.GetLabel() {
+				switch label.GetName() {
+				case labelHostname:
+				case labelInstanceName:
+					instance = label.GetValue()
+				default:
+					attributes = append(attributes, KeyValue{Key: label.GetName(), Value: AnyValue{StringValue: label.GetValue()}})
+				}
+			}
+			if resources[instance] == nil {
+				resources[instance] = map[string]*Metric{}
+			}
+			metric, ok := resources[instance][family.GetName()]
+			if !ok {
+				metric = &Metric{Name: family.GetName(), Description: family.GetHelp()}
+				resources[instance][family.GetName()] = metric
+			}
+			addDataPoint(metric, family.GetType(), m, attributes, start, timestamp)
+		}
+	}
+
+	instances := make([]string, 0, len(resources))
+	for instance := range resources {
+		instances = append(instances, instance)
+	}
+	sort.Strings(instances)
+	request := &ExportMetricsServiceRequest{}
+	for _, instance := range instances {
+		resource := Resource{Attributes: []KeyValue{
+			{Key: attributeServiceName, Value: AnyValue{StringValue: serviceName}},
+			{Key: attributeHostName, Value: AnyValue{StringValue: e.hostname}},
+		}}
+		if instance != "" {
+			resource.Attributes = append(resource.Attributes, KeyValue{Key: attributeInstanceName, Value: AnyValue{StringValue: instance}})
+		}
+		names := make([]string, 0, len(resources[instance]))
+		for name := range resources[instance] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		scope := ScopeMetrics{Scope: Scope{Name: scopeName}}
+		for _, name := range names {
+			scope.Metrics = append(scope.Metrics, *resources[instance][name])
+		}
+		request.ResourceMetrics = append(request.ResourceMetrics, ResourceMetrics{
+			Resource:     resource,
+			ScopeMetrics: []ScopeMetrics{scope},
+		})
+	}
+	return request
+}
+
+// addDataPoint converts one prometheus metric into an OTLP data point.
+// Values that can not be encoded in JSON (NaN and infinity) are skipped.
+func addDataPoint(metric *Metric, kind dto.MetricType, m *dto.Metric, attributes []KeyValue, start string, timestamp string) {
+	switch kind {
+	case dto.MetricType_COUNTER:
+		if !valid(m.GetCounter().GetValue()) {
+			return
+		}
+		if metric.Sum == nil {
+			metric.Sum = &Sum{AggregationTemporality: AggregationTemporalityCumulative, IsMonotonic: true}
+		}
+		metric.Sum.DataPoints = append(metric.Sum.DataPoints, NumberDataPoint{
+			Attributes:        attributes,
+			StartTimeUnixNano: start,
+			TimeUnixNano:      timestamp,
+			AsDouble:          m.GetCounter().GetValue(),
+		})
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		value := m.GetGauge().GetValue()
+		if kind == dto.MetricType_UNTYPED {
+			value = m.GetUntyped().GetValue()
+		}
+		if !valid(value) {
+			return
+		}
+		if metric.Gauge == nil {
+			metric.Gauge = &Gauge{}
+		}
+		metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, NumberDataPoint{
+			Attributes:   attributes,
+			TimeUnixNano: timestamp,
+			AsDouble:     value,
+		})
+	case dto.MetricType_HISTOGRAM:
+		h := m.GetHistogram()
+		if !valid(h.GetSampleSum()) {
+			return
+		}
+		if metric.Histogram == nil {
+			metric.Histogram = &Histogram{AggregationTemporality: AggregationTemporalityCumulative}
+		}
+		// Prometheus buckets are cumulative, OTLP buckets are not and
+		// have an implicit +Inf bucket.
+		point := HistogramDataPoint{
+			Attributes:        attributes,
+			StartTimeUnixNano: start,
+			TimeUnixNano:      timestamp,
+			Count:             strconv.FormatUint(h.GetSampleCount(), 10),
+			Sum:               h.GetSampleSum(),
+		}
+		previous := uint64(0)
+		for _, bucket := range h.GetBucket() {
+			if math.IsInf(bucket.GetUpperBound(), 1) {
+				continue
+			}
+			point.ExplicitBounds = append(point.ExplicitBounds, bucket.GetUpperBound())
+			point.BucketCounts = append(point.BucketCounts, strconv.FormatUint(bucket.GetCumulativeCount()-previous, 10))
+			previous = bucket.GetCumulativeCount()
+		}
+		point.BucketCounts = append(point.BucketCounts, strconv.FormatUint(h.GetSampleCount()-previous, 10))
+		metric.Histogram.DataPoints = append(metric.Histogram.DataPoints, point)
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		if !valid(s.GetSampleSum()) {
+			return
+		}
+		if metric.Summary == nil {
+			metric.Summary = &Summary{}
+		}
+		point := SummaryDataPoint{
+			Attributes:        attributes,
+			StartTimeUnixNano: start,
+			TimeUnixNano:      timestamp,
+			Count:             strconv.FormatUint(s.GetSampleCount(), 10),
+			Sum:               s.GetSampleSum(),
+		}
+		for _, q := range s.GetQuantile() {
+			if valid(q.GetValue()) {
+				point.QuantileValues = append(point.QuantileValues, QuantileValue{Quantile: q.GetQuantile(), Value: q.GetValue()})
+			}
+		}
+		metric.Summary.DataPoints = append(metric.Summary.DataPoints, point)
+	}
+}
+
+func valid(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// collector is a stand-in for an OpenTelemetry collector.
+type collector struct {
+	requests chan ExportMetricsServiceRequest
+	headers  chan http.Header
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	c := &collector{
+		requests: make(chan ExportMetricsServiceRequest, 10),
+		headers:  make(chan http.Header, 10),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != metricsPath || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var request ExportMetricsServiceRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.requests <- request
+		c.headers <- r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return c, server
+}
+
+func newTestRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	sessions := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "sessions_established",
+		Help:        "Established sessions",
+		ConstLabels: prometheus.Labels{labelHostname: "blaster"},
+	}, []string{labelInstanceName})
+	sessions.WithLabelValues("test").Set(8)
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_http_requests_total",
+		Help: "HTTP requests",
+	}, []string{"code"})
+	requests.WithLabelValues("200").Add(3)
+	duration := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "metrics_poll_duration_seconds",
+		Help:    "Poll duration",
+		Buckets: []float64{0.1, 1},
+	})
+	duration.Observe(0.05)
+	duration.Observe(0.5)
+	duration.Observe(5)
+	registry.MustRegister(sessions, requests, duration)
+	return registry
+}
+
+func attribute(attributes []KeyValue, key string) string {
+	for _, kv := range attributes {
+		if kv.Key == key {
+			return kv.Value.StringValue
+		}
+	}
+	return ""
+}
+
+func TestExporter_Push(t *testing.T) {
+	c, server := newCollector(t)
+	e, err := NewExporter(newTestRegistry(), server.URL, WithHeaders(map[string]string{"Authorization": "Bearer token"}))
+	require.NoError(t, err)
+	require.NoError(t, e.Push(context.Background()))
+
+	request := <-c.requests
+	require.Equal(t, "Bearer token", (<-c.headers).Get("Authorization"))
+	require.Len(t, request.ResourceMetrics, 2)
+
+	// Controller resource.
+	controller := request.ResourceMetrics[0]
+	require.Equal(t, serviceName, attribute(controller.Resource.Attributes, attributeServiceName))
+	require.Equal(t, e.hostname, attribute(controller.Resource.Attributes, attributeHostName))
+	require.Empty(t, attribute(controller.Resource.Attributes, attributeInstanceName))
+	metrics := controller.ScopeMetrics[0].Metrics
+	require.Len(t, metrics, 2)
+	require.Equal(t, "controller_http_requests_total", metrics[0].Name)
+	require.NotNil(t, metrics[0].Sum)
+	require.True(t, metrics[0].Sum.IsMonotonic)
+	require.Equal(t, float64(3), metrics[0].Sum.DataPoints[0].AsDouble)
+	require.Equal(t, "200", attribute(metrics[0].Sum.DataPoints[0].Attributes, "code"))
+	require.Equal(t, "metrics_poll_duration_seconds", metrics[1].Name)
+	require.NotNil(t, metrics[1].Histogram)
+	require.Equal(t, "3", metrics[1].Histogram.DataPoints[0].Count)
+	require.Equal(t, []float64{0.1, 1}, metrics[1].Histogram.DataPoints[0].ExplicitBounds)
+	require.Equal(t, []string{"1", "1", "1"}, metrics[1].Histogram.DataPoints[0].BucketCounts)
+
+	// Instance resource.
+	instance := request.ResourceMetrics[1]
+	require.Equal(t, "test", attribute(instance.Resource.Attributes, attributeInstanceName))
+	metrics = instance.ScopeMetrics[0].Metrics
+	require.Len(t, metrics, 1)
+	require.Equal(t, "sessions_established", metrics[0].Name)
+	require.NotNil(t, metrics[0].Gauge)
+	require.Equal(t, float64(8), metrics[0].Gauge.DataPoints[0].AsDouble)
+	require.Empty(t, metrics[0].Gauge.DataPoints[0].Attributes)
+}
+
+func TestExporter_PushError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	e, err := NewExporter(newTestRegistry(), server.URL)
+	require.NoError(t, err)
+	require.Error(t, e.Push(context.Background()))
+}
+
+func TestExporter_Start(t *testing.T) {
+	c, server := newCollector(t)
+	e, err := NewExporter(newTestRegistry(), server.URL+metricsPath, WithInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	e.Start()
+	defer e.Stop()
+	select {
+	case <-c.requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no metrics pushed")
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{name: "default_path", endpoint: "http://localhost:4318", want: "http://localhost:4318/v1/metrics"},
+		{name: "custom_path", endpoint: "https://collector/otlp/v1/metrics", want: "https://collector/otlp/v1/metrics"},
+		{name: "invalid_scheme", endpoint: "localhost:4318", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewExporter(prometheus.NewRegistry(), tt.endpoint)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, e.endpoint)
+		})
+	}
+}
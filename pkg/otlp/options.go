@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package otlp
+
+import "time"
+
+// Option helps to configure the Exporter with options.
+type Option func(e *Exporter)
+
+// WithInterval is the option to set the push interval.
+func WithInterval(interval time.Duration) Option {
+	return func(e *Exporter) {
+		if interval > 0 {
+			e.interval = interval
+		}
+	}
+}
+
+// WithHeaders is the option to send additional HTTP headers, e.g. for authentication.
+func WithHeaders(headers map[string]string) Option {
+	return func(e *Exporter) {
+		for k, v := range headers {
+			e.headers[k] = v
+		}
+	}
+}
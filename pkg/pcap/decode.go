@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package pcap
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// Protocols recognised by Decode.
+const (
+	ProtocolARP            = "arp"
+	ProtocolPPPoEDiscovery = "pppoe-discovery"
+	ProtocolPPPoESession   = "pppoe-session"
+	ProtocolLCP            = "lcp"
+	ProtocolPAP            = "pap"
+	ProtocolCHAP           = "chap"
+	ProtocolIPCP           = "ipcp"
+	ProtocolIP6CP          = "ip6cp"
+	ProtocolIPv4           = "ipv4"
+	ProtocolIPv6           = "ipv6"
+	ProtocolUDP            = "udp"
+	ProtocolTCP            = "tcp"
+	ProtocolDHCP           = "dhcp"
+	ProtocolDHCPv6         = "dhcpv6"
+	ProtocolICMP           = "icmp"
+	ProtocolICMPv6         = "icmpv6"
+	ProtocolICMPv6RA       = "icmpv6-ra"
+	ProtocolIGMP           = "igmp"
+	ProtocolL2TP           = "l2tp"
+)
+
+const (
+	etherTypeIPv4           = 0x0800
+	etherTypeARP            = 0x0806
+	etherTypeVLAN           = 0x8100
+	etherTypeQinQ           = 0x88a8
+	etherTypeQinQLegacy     = 0x9100
+	etherTypeIPv6           = 0x86dd
+	etherTypePPPoEDiscovery = 0x8863
+	etherTypePPPoESession   = 0x8864
+
+	pppIPv4  = 0x0021
+	pppIPv6  = 0x0057
+	pppIPCP  = 0x8021
+	pppIP6CP = 0x8057
+	pppLCP   = 0xc021
+	pppPAP   = 0xc023
+	pppCHAP  = 0xc223
+
+	ipProtocolICMP   = 1
+	ipProtocolIGMP   = 2
+	ipProtocolTCP    = 6
+	ipProtocolUDP    = 17
+	ipProtocolICMPv6 = 58
+
+	icmpv6RouterAdvertisement = 134
+
+	portDHCPServer   = 67
+	portDHCPClient   = 68
+	portDHCPv6Client = 546
+	portDHCPv6Server = 547
+	portL2TP         = 1701
+)
+
+// Layers are the decoded headers of an ethernet packet.
+type Layers struct {
+	SrcMAC, DstMAC net.HardwareAddr
+	// VLANs are the VLAN identifiers from outer to inner.
+	VLANs []uint16
+	// EtherType is the ether type after the VLAN tags.
+	EtherType uint16
+	// PPPProtocol is the PPP protocol of PPPoE session packets.
+	PPPProtocol  uint16
+	SrcIP, DstIP net.IP
+	IPProtocol   uint8
+	SrcPort      uint16
+	DstPort      uint16
+	Protocols    []string
+}
+
+// Has returns true if the packet contains the protocol.
+func (l *Layers) Has(protocol string) bool {
+	for _, p := range l.Protocols {
+		if p == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// Decode decodes the headers of an ethernet packet as far as possible,
+// truncated packets are decoded up to the last complete header.
+func Decode(p *Packet) *Layers {
+	l := &Layers{}
+	if p.LinkType != LinkTypeEthernet || len(p.Data) < 14 {
+		return l
+	}
+	data := p.Data
+	l.DstMAC = net.HardwareAddr(data[0:6])
+	l.SrcMAC = net.HardwareAddr(data[6:12])
+	l.EtherType = binary.BigEndian.Uint16(data[12:14])
+	data = data[14:]
+	for l.EtherType == etherTypeVLAN || l.EtherType == etherTypeQinQ || l.EtherType == etherTypeQinQLegacy {
+		if len(data) < 4 {
+			return l
+		}
+		l.VLANs = append(l.VLANs, binary.BigEndian.Uint16(data[0:2])&0x0fff)
+		l.EtherType = binary.BigEndian.Uint16(data[2:4])
+		data = data[4:]
+	}
+
+	switch l.EtherType {
+	case etherTypeARP:
+		l.add(ProtocolARP)
+	case etherTypePPPoEDiscovery:
+		l.add(ProtocolPPPoEDiscovery)
+	case etherTypePPPoESession:
+		l.add(ProtocolPPPoESession)
+		if len(data) < 8 {
+			return l
+		}
+		l.PPPProtocol = binary.BigEndian.Uint16(data[6:8])
+		l.decodePPP(data[8:])
+	case etherTypeIPv4:
+		l.decodeIPv4(data)
+	case etherTypeIPv6:
+		l.decodeIPv6(data)
+	}
+	return l
+}
+
+func (l *Layers) add(protocol string) {
+	l.Protocols = append(l.Protocols, protocol)
+}
+
+func (l *Layers) decodePPP(data []byte) {
+	switch l.PPPProtocol {
+	case pppLCP:
+		l.add(ProtocolLCP)
+	case pppPAP:
+		l.add(ProtocolPAP)
+	case pppCHAP:
+		l.add(ProtocolCHAP)
+	case pppIPCP:
+		l.add(ProtocolIPCP)
+	case pppIP6CP:
+		l.add(ProtocolIP6CP)
+	case pppIPv4:
+		l.decodeIPv4(data)
+	case pppIPv6:
+		l.decodeIPv6(data)
+	}
+}
+
+func (l *Layers) decodeIPv4(data []byte) {
+	l.add(ProtocolIPv4)
+	if len(data) < 20 {
+		return
+	}
+	headerLength := int(data[0]&0x0f) * 4
+	if headerLength < 20 || len(data) < headerLength {
+		return
+	}
+	l.SrcIP = net.IP(data[12:16])
+	l.DstIP = net.IP(data[16:20])
+	l.IPProtocol = data[9]
+	// Only the first fragment contains the transport header.
+	if binary.BigEndian.Uint16(data[6:8])&0x1fff != 0 {
+		return
+	}
+	l.decodeTransport(data[headerLength:])
+}
+
+func (l *Layers) decodeIPv6(data []byte) {
+	l.add(ProtocolIPv6)
+	if len(data) < 40 {
+		return
+	}
+	l.SrcIP = net.IP(data[8:24])
+	l.DstIP = net.IP(data[24:40])
+	next := data[6]
+	data = data[40:]
+	// Skip the extension headers, e.g. the hop-by-hop options of MLD.
+	for {
+		switch next {
+		case 0, 43, 60:
+			if len(data) < 8 {
+				return
+			}
+			length := (int(data[1]) + 1) * 8
+			if len(data) < length {
+				return
+			}
+			next = data[0]
+			data = data[length:]
+			continue
+		case 44:
+			if len(data) < 8 {
+				return
+			}
+			if binary.BigEndian.Uint16(data[2:4])&0xfff8 != 0 {
+				return
+			}
+			next = data[0]
+			data = data[8:]
+			continue
+		}
+		break
+	}
+	l.IPProtocol = next
+	l.decodeTransport(data)
+}
+
+func (l *Layers) decodeTransport(data []byte) {
+	switch l.IPProtocol {
+	case ipProtocolICMP:
+		l.add(ProtocolICMP)
+	case ipProtocolIGMP:
+		l.add(ProtocolIGMP)
+	case ipProtocolICMPv6:
+		l.add(ProtocolICMPv6)
+		if len(data) > 0 && data[0] == icmpv6RouterAdvertisement {
+			l.add(ProtocolICMPv6RA)
+		}
+	case ipProtocolTCP:
+		l.add(ProtocolTCP)
+		if len(data) >= 4 {
+			l.SrcPort = binary.BigEndian.Uint16(data[0:2])
+			l.DstPort = binary.BigEndian.Uint16(data[2:4])
+		}
+	case ipProtocolUDP:
+		l.add(ProtocolUDP)
+		if len(data) < 8 {
+			return
+		}
+		l.SrcPort = binary.BigEndian.Uint16(data[0:2])
+		l.DstPort = binary.BigEndian.Uint16(data[2:4])
+		switch {
+		case l.port(portDHCPServer) || l.port(portDHCPClient):
+			l.add(ProtocolDHCP)
+		case l.port(portDHCPv6Server) || l.port(portDHCPv6Client):
+			l.add(ProtocolDHCPv6)
+		case l.port(portL2TP):
+			l.add(ProtocolL2TP)
+		}
+	}
+}
+
+// port returns true if the source or destination port is equal to port.
+func (l *Layers) port(port uint16) bool {
+	return l.SrcPort == port || l.DstPort == port
+}
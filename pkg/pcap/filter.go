@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package pcap
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// filterProtocols are the protocols supported by ParseFilter.
+var filterProtocols = map[string]bool{
+	ProtocolARP: true, ProtocolPPPoEDiscovery: true, ProtocolPPPoESession: true,
+	ProtocolLCP: true, ProtocolPAP: true, ProtocolCHAP: true, ProtocolIPCP: true,
+	ProtocolIP6CP: true, ProtocolIPv4: true, ProtocolIPv6: true, ProtocolUDP: true,
+	ProtocolTCP: true, ProtocolDHCP: true, ProtocolDHCPv6: true, ProtocolICMP: true,
+	ProtocolICMPv6: true, ProtocolICMPv6RA: true, ProtocolIGMP: true, ProtocolL2TP: true,
+}
+
+// Time is a filter bound, either absolute or relative to the first packet.
+type Time struct {
+	Time   time.Time
+	Offset time.Duration
+	// Relative is true if the bound is the offset to the first packet.
+	Relative bool
+}
+
+// IsZero returns true if the bound is not set.
+func (t Time) IsZero() bool {
+	return !t.Relative && t.Time.IsZero()
+}
+
+// resolve returns the absolute time of the bound.
+func (t Time) resolve(first time.Time) time.Time {
+	if t.Relative {
+		return first.Add(t.Offset)
+	}
+	return t.Time
+}
+
+// ParseTime parses an RFC 3339 timestamp or an offset in seconds to the first packet.
+func ParseTime(v string) (Time, error) {
+	if v == "" {
+		return Time{}, nil
+	}
+	if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+		return Time{Offset: time.Duration(seconds * float64(time.Second)), Relative: true}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return Time{}, fmt.Errorf("invalid time %q", v)
+	}
+	return Time{Time: t}, nil
+}
+
+// Filter selects packets. A packet matches if it is in the time window,
+// contains one of the protocols (if any) and one of the VLANs (if any).
+type Filter struct {
+	Start      Time
+	End        Time
+	Protocols  []string
+	VLANs      []uint16
+	MaxPackets int
+}
+
+// ParseFilter parses a comma separated list of protocols and
+// VLAN identifiers (vlan:<id>), e.g. pppoe-discovery,dhcp,vlan:100.
+func ParseFilter(v string) (Filter, error) {
+	var f Filter
+	for _, term := range strings.Split(v, ",") {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term == "" {
+			continue
+		}
+		if id, ok := strings.CutPrefix(term, "vlan:"); ok {
+			vlan, err := strconv.ParseUint(id, 10, 12)
+			if err != nil {
+				return Filter{}, fmt.Errorf("invalid vlan %q", id)
+			}
+			f.VLANs = append(f.VLANs, uint16(vlan))
+			continue
+		}
+		if !filterProtocols[term] {
+			return Filter{}, fmt.Errorf("unknown filter %q", term)
+		}
+		f.Protocols = append(f.Protocols, term)
+	}
+	return f, nil
+}
+
+// match returns true if the packet matches the protocols and VLANs of the filter.
+func (f *Filter) match(p *Packet) bool {
+	if len(f.Protocols) == 0 && len(f.VLANs) == 0 {
+		return true
+	}
+	l := Decode(p)
+	if len(f.Protocols) > 0 {
+		found := false
+		for _, protocol := range f.Protocols {
+			if l.Has(protocol) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.VLANs) > 0 {
+		for _, want := range f.VLANs {
+			for _, vlan := range l.VLANs {
+				if vlan == want {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// reorderTolerance is how long packets are read after the end of the time window
+// of a pcapng file, whose packets of multiple interfaces may be slightly out of order.
+const reorderTolerance = time.Second
+
+// Slice copies the header blocks and the packets matching the filter from the reader to w.
+// The output has the same format as the input. It returns the number of packets written.
+// Reading stops at the first packet after the end of the time window.
+func Slice(reader *Reader, w io.Writer, f Filter) (int, error) {
+	var first time.Time
+	var start, end time.Time
+	tolerance := time.Duration(0)
+	if reader.Format() == FormatPcapng {
+		tolerance = reorderTolerance
+	}
+	packets := 0
+	for {
+		block, err := reader.Next()
+		if err == io.EOF {
+			return packets, nil
+		}
+		if err != nil {
+			return packets, err
+		}
+		if p := block.Packet; p != nil {
+			if first.IsZero() && !p.Timestamp.IsZero() {
+				first = p.Timestamp
+				start = f.Start.resolve(first)
+				end = f.End.resolve(first)
+			}
+			if !f.Start.IsZero() && p.Timestamp.Before(start) {
+				continue
+			}
+			if !f.End.IsZero() && p.Timestamp.After(end) {
+				if p.Timestamp.After(end.Add(tolerance)) {
+					return packets, nil
+				}
+				continue
+			}
+			if !f.match(p) {
+				continue
+			}
+			packets++
+		}
+		if _, err := w.Write(block.Raw); err != nil {
+			return packets, err
+		}
+		if f.MaxPackets > 0 && packets >= f.MaxPackets {
+			return packets, nil
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package pcap
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    Filter
+		wantErr bool
+	}{
+		{name: "empty", filter: "", want: Filter{}},
+		{name: "protocols", filter: "PPPoE-Discovery, dhcp", want: Filter{Protocols: []string{ProtocolPPPoEDiscovery, ProtocolDHCP}}},
+		{name: "vlans", filter: "vlan:100,lcp,vlan:200", want: Filter{Protocols: []string{ProtocolLCP}, VLANs: []uint16{100, 200}}},
+		{name: "unknown_protocol", filter: "bgp", wantErr: true},
+		{name: "invalid_vlan", filter: "vlan:4096", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilter(tt.filter)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Time
+		wantErr bool
+	}{
+		{name: "empty", value: "", want: Time{}},
+		{name: "relative", value: "1.5", want: Time{Offset: 1500 * time.Millisecond, Relative: true}},
+		{name: "absolute", value: "2025-01-01T10:00:02Z", want: Time{Time: testStart.Add(2 * time.Second)}},
+		{name: "invalid", value: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSlice(t *testing.T) {
+	packets := testPackets()
+	tests := []struct {
+		name   string
+		filter string
+		start  string
+		end    string
+		max    int
+		// want are the indexes of the expected test packets.
+		want []int
+	}{
+		{name: "all", want: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{name: "protocols", filter: "pppoe-discovery,dhcp", want: []int{0, 1, 5}},
+		{name: "vlan", filter: "vlan:200", want: []int{5, 6, 7}},
+		{name: "inner_vlan", filter: "vlan:1", want: []int{5, 6, 7}},
+		{name: "protocol_and_vlan", filter: "ipv4,vlan:100", want: []int{4}},
+		{name: "relative_window", start: "2", end: "4", want: []int{2, 3, 4}},
+		{name: "absolute_window", start: "2025-01-01T10:00:09Z", want: []int{9, 10}},
+		{name: "max_packets", filter: "vlan:100", max: 2, want: []int{0, 1}},
+	}
+	for _, format := range []struct {
+		name  string
+		write func([]*Packet) []byte
+	}{{"pcap", writePcap}, {"pcapng", writePcapng}} {
+		data := format.write(packets)
+		for _, tt := range tests {
+			t.Run(format.name+"/"+tt.name, func(t *testing.T) {
+				f, err := ParseFilter(tt.filter)
+				require.NoError(t, err)
+				f.Start, err = ParseTime(tt.start)
+				require.NoError(t, err)
+				f.End, err = ParseTime(tt.end)
+				require.NoError(t, err)
+				f.MaxPackets = tt.max
+
+				reader, err := NewReader(bytes.NewReader(data))
+				require.NoError(t, err)
+				var out bytes.Buffer
+				n, err := Slice(reader, &out, f)
+				require.NoError(t, err)
+				require.Equal(t, len(tt.want), n)
+
+				_, got := readPackets(t, out.Bytes())
+				require.Len(t, got, len(tt.want))
+				for i, index := range tt.want {
+					require.Equal(t, packets[index].Data, got[i].Data)
+				}
+			})
+		}
+	}
+}
+
+// failingReader fails the test if the slice reads beyond the data.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read beyond the end of the time window")
+}
+
+func TestSlice_StopsAfterEnd(t *testing.T) {
+	packets := testPackets()
+	for _, format := range []struct {
+		name  string
+		write func([]*Packet) []byte
+	}{{"pcap", writePcap}, {"pcapng", writePcapng}} {
+		t.Run(format.name, func(t *testing.T) {
+			reader, err := NewReader(io.MultiReader(bytes.NewReader(format.write(packets)), failingReader{}))
+			require.NoError(t, err)
+			f := Filter{}
+			f.End, err = ParseTime("4")
+			require.NoError(t, err)
+			n, err := Slice(reader, io.Discard, f)
+			require.NoError(t, err)
+			require.Equal(t, 5, n)
+		})
+	}
+}
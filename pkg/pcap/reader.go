@@ -0,0 +1,298 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+
+// Package pcap reads pcap and pcapng capture files as written by the bngblaster.
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// Classic pcap magic numbers with microsecond and nanosecond timestamps.
+	magicMicroseconds = 0xa1b2c3d4
+	magicNanoseconds  = 0xa1b23c4d
+
+	// pcapng block types.
+	blockSectionHeader        = 0x0a0d0d0a
+	blockInterfaceDescription = 0x00000001
+	blockSimplePacket         = 0x00000003
+	blockEnhancedPacket       = 0x00000006
+	byteOrderMagic            = 0x1a2b3c4d
+
+	// optionTimestampResolution is the if_tsresol option of the interface description block.
+	optionTimestampResolution = 9
+
+	// maxBlockSize protects against corrupted length fields.
+	maxBlockSize = 64 << 20
+)
+
+// LinkTypeEthernet is the only link type decoded by this package.
+const LinkTypeEthernet = 1
+
+// Format is the capture file format.
+type Format int
+
+const (
+	// FormatPcap is the classic libpcap format.
+	FormatPcap Format = iota
+	// FormatPcapng is the pcap next generation format.
+	FormatPcapng
+)
+
+// ErrInvalidFormat is returned if the file is neither pcap nor pcapng.
+var ErrInvalidFormat = errors.New("invalid pcap file")
+
+// Packet is a captured packet.
+type Packet struct {
+	Timestamp time.Time
+	// Interface is the index of the pcapng interface (always 0 for pcap).
+	Interface int
+	LinkType  uint16
+	// Length is the original length of the packet on the wire.
+	Length int
+	// Data is the captured part of the packet.
+	Data []byte
+}
+
+// Block is one block of a pcapng file or the file header or one record of a pcap file.
+type Block struct {
+	// Raw is the unmodified block, it can be written as is to a file of the same format.
+	Raw []byte
+	// Packet is set for packet blocks.
+	Packet *Packet
+}
+
+// iface is a pcapng interface.
+type iface struct {
+	linkType uint16
+	snapLen  uint32
+	// resolution of the timestamps as power of base (10 or 2).
+	resolution uint8
+	base       uint64
+}
+
+// Reader reads the blocks of a pcap or pcapng file.
+type Reader struct {
+	r      *bufio.Reader
+	format Format
+	order  binary.ByteOrder
+	// pcap
+	header   []byte
+	linkType uint16
+	nano     bool
+	// pcapng
+	interfaces []iface
+}
+
+// NewReader detects the format and returns a reader.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{r: bufio.NewReaderSize(r, 1<<20)}
+	magic, err := reader.r.Peek(4)
+	if err != nil {
+		return nil, ErrInvalidFormat
+	}
+	if binary.LittleEndian.Uint32(magic) == blockSectionHeader {
+		reader.format = FormatPcapng
+		return reader, nil
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		switch order.Uint32(magic) {
+		case magicMicroseconds:
+			reader.order = order
+		case magicNanoseconds:
+			reader.order = order
+			reader.nano = true
+		default:
+			continue
+		}
+		reader.format = FormatPcap
+		reader.header = make([]byte, 24)
+		if _, err := io.ReadFull(reader.r, reader.header); err != nil {
+			return nil, ErrInvalidFormat
+		}
+		reader.linkType = uint16(order.Uint32(reader.header[20:24]))
+		return reader, nil
+	}
+	return nil, ErrInvalidFormat
+}
+
+// Format returns the format of the file.
+func (r *Reader) Format() Format {
+	return r.format
+}
+
+// Next returns the next block, the file header of a pcap file is returned first.
+// It returns io.EOF at the end of the file.
+func (r *Reader) Next() (Block, error) {
+	if r.format == FormatPcap {
+		return r.nextRecord()
+	}
+	return r.nextBlock()
+}
+
+func (r *Reader) nextRecord() (Block, error) {
+	if r.header != nil {
+		header := r.header
+		r.header = nil
+		return Block{Raw: header}, nil
+	}
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			// Truncated while the bngblaster is writing.
+			return Block{}, io.EOF
+		}
+		return Block{}, err
+	}
+	capLen := r.order.Uint32(header[8:12])
+	if capLen > maxBlockSize {
+		return Block{}, fmt.Errorf("%w: record length %d", ErrInvalidFormat, capLen)
+	}
+	raw := make([]byte, 16+int(capLen))
+	copy(raw, header)
+	if _, err := io.ReadFull(r.r, raw[16:]); err != nil {
+		return Block{}, io.EOF
+	}
+	fraction := int64(r.order.Uint32(header[4:8]))
+	if !r.nano {
+		fraction *= int64(time.Microsecond)
+	}
+	return Block{Raw: raw, Packet: &Packet{
+		Timestamp: time.Unix(int64(r.order.Uint32(header[0:4])), fraction).UTC(),
+		LinkType:  r.linkType,
+		Length:    int(r.order.Uint32(header[12:16])),
+		Data:      raw[16:],
+	}}, nil
+}
+
+func (r *Reader) nextBlock() (Block, error) {
+	header, err := r.r.Peek(12)
+	if err != nil {
+		// A truncated block is treated as end of file, the
+		// bngblaster might still be writing.
+		return Block{}, err
+	}
+	if binary.LittleEndian.Uint32(header[0:4]) == blockSectionHeader {
+		switch binary.LittleEndian.Uint32(header[8:12]) {
+		case byteOrderMagic:
+			r.order = binary.LittleEndian
+		case 0x4d3c2b1a:
+			r.order = binary.BigEndian
+		default:
+			return Block{}, fmt.Errorf("%w: invalid byte order magic", ErrInvalidFormat)
+		}
+		// Each section has its own interfaces.
+		r.interfaces = nil
+	}
+	if r.order == nil {
+		return Block{}, ErrInvalidFormat
+	}
+	blockType := r.order.Uint32(header[0:4])
+	length := r.order.Uint32(header[4:8])
+	if length < 12 || length%4 != 0 || length > maxBlockSize {
+		return Block{}, fmt.Errorf("%w: block length %d", ErrInvalidFormat, length)
+	}
+	raw := make([]byte, length)
+	if _, err := io.ReadFull(r.r, raw); err != nil {
+		return Block{}, io.EOF
+	}
+	body := raw[8 : length-4]
+	block := Block{Raw: raw}
+	switch blockType {
+	case blockInterfaceDescription:
+		if len(body) < 8 {
+			return Block{}, fmt.Errorf("%w: interface description block", ErrInvalidFormat)
+		}
+		i := iface{
+			linkType:   r.order.Uint16(body[0:2]),
+			snapLen:    r.order.Uint32(body[4:8]),
+			resolution: 6,
+			base:       10,
+		}
+		r.parseOptions(body[8:], func(code uint16, value []byte) {
+			if code == optionTimestampResolution && len(value) > 0 {
+				i.resolution = value[0] & 0x7f
+				if value[0]&0x80 != 0 {
+					i.base = 2
+				}
+			}
+		})
+		r.interfaces = append(r.interfaces, i)
+	case blockEnhancedPacket:
+		if len(body) < 20 {
+			return Block{}, fmt.Errorf("%w: enhanced packet block", ErrInvalidFormat)
+		}
+		id := int(r.order.Uint32(body[0:4]))
+		if id >= len(r.interfaces) {
+			return Block{}, fmt.Errorf("%w: unknown interface %d", ErrInvalidFormat, id)
+		}
+		capLen := int(r.order.Uint32(body[12:16]))
+		if capLen > len(body)-20 {
+			return Block{}, fmt.Errorf("%w: enhanced packet length %d", ErrInvalidFormat, capLen)
+		}
+		units := uint64(r.order.Uint32(body[4:8]))<<32 | uint64(r.order.Uint32(body[8:12]))
+		block.Packet = &Packet{
+			Timestamp: r.interfaces[id].timestamp(units),
+			Interface: id,
+			LinkType:  r.interfaces[id].linkType,
+			Length:    int(r.order.Uint32(body[16:20])),
+			Data:      body[20 : 20+capLen],
+		}
+	case blockSimplePacket:
+		if len(body) < 4 || len(r.interfaces) == 0 {
+			return Block{}, fmt.Errorf("%w: simple packet block", ErrInvalidFormat)
+		}
+		length := int(r.order.Uint32(body[0:4]))
+		capLen := len(body) - 4
+		if length < capLen {
+			capLen = length
+		}
+		if snapLen := int(r.interfaces[0].snapLen); snapLen > 0 && snapLen < capLen {
+			capLen = snapLen
+		}
+		// Simple packet blocks have no timestamp.
+		block.Packet = &Packet{
+			LinkType: r.interfaces[0].linkType,
+			Length:   length,
+			Data:     body[4 : 4+capLen],
+		}
+	}
+	return block, nil
+}
+
+// parseOptions calls f for every option of a pcapng block.
+func (r *Reader) parseOptions(options []byte, f func(code uint16, value []byte)) {
+	for len(options) >= 4 {
+		code := r.order.Uint16(options[0:2])
+		length := int(r.order.Uint16(options[2:4]))
+		if code == 0 || 4+length > len(options) {
+			return
+		}
+		f(code, options[4:4+length])
+		padded := (length + 3) &^ 3
+		if 4+padded > len(options) {
+			return
+		}
+		options = options[4+padded:]
+	}
+}
+
+// timestamp converts the timestamp units of the interface into a time.
+func (i iface) timestamp(units uint64) time.Time {
+	if i.base == 10 && i.resolution <= 9 {
+		scale := uint64(math.Pow10(int(i.resolution)))
+		seconds := units / scale
+		nanoseconds := (units % scale) * uint64(math.Pow10(9-int(i.resolution)))
+		return time.Unix(int64(seconds), int64(nanoseconds)).UTC()
+	}
+	seconds := float64(units) / math.Pow(float64(i.base), float64(i.resolution))
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*1e9)).UTC()
+}
@@ -0,0 +1,277 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testStart  = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	clientMAC  = []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	serverMAC  = []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x02}
+	broadcast  = []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	clientIPv4 = []byte{10, 0, 0, 1}
+	serverIPv4 = []byte{10, 0, 0, 2}
+)
+
+// ethernet builds an ethernet frame with the given VLAN tags.
+func ethernet(src []byte, dst []byte, vlans []uint16, etherType uint16, payload []byte) []byte {
+	frame := append([]byte{}, dst...)
+	frame = append(frame, src...)
+	for _, vlan := range vlans {
+		frame = binary.BigEndian.AppendUint16(frame, etherTypeVLAN)
+		frame = binary.BigEndian.AppendUint16(frame, vlan)
+	}
+	frame = binary.BigEndian.AppendUint16(frame, etherType)
+	return append(frame, payload...)
+}
+
+// ipv4 builds an IPv4 header with the given payload.
+func ipv4(src []byte, dst []byte, protocol uint8, payload []byte) []byte {
+	header := make([]byte, 20)
+	header[0] = 0x45
+	binary.BigEndian.PutUint16(header[2:4], uint16(20+len(payload)))
+	header[8] = 64
+	header[9] = protocol
+	copy(header[12:16], src)
+	copy(header[16:20], dst)
+	return append(header, payload...)
+}
+
+// ipv6 builds an IPv6 header with the given payload.
+func ipv6(next uint8, payload []byte) []byte {
+	header := make([]byte, 40)
+	header[0] = 0x60
+	binary.BigEndian.PutUint16(header[4:6], uint16(len(payload)))
+	header[6] = next
+	header[7] = 255
+	header[8], header[9], header[23] = 0xfe, 0x80, 1
+	header[24], header[25], header[39] = 0xff, 0x02, 1
+	return append(header, payload...)
+}
+
+// udp builds a UDP header with the given payload.
+func udp(src uint16, dst uint16, payload []byte) []byte {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint16(header[0:2], src)
+	binary.BigEndian.PutUint16(header[2:4], dst)
+	binary.BigEndian.PutUint16(header[4:6], uint16(8+len(payload)))
+	return append(header, payload...)
+}
+
+// pppoe builds a PPPoE header, the PPP protocol is added for session packets.
+func pppoe(code uint8, session uint16, protocol uint16, payload []byte) []byte {
+	header := []byte{0x11, code}
+	header = binary.BigEndian.AppendUint16(header, session)
+	length := len(payload)
+	if protocol != 0 {
+		length += 2
+	}
+	header = binary.BigEndian.AppendUint16(header, uint16(length))
+	if protocol != 0 {
+		header = binary.BigEndian.AppendUint16(header, protocol)
+	}
+	return append(header, payload...)
+}
+
+// testPackets are a PPPoE and DHCP session setup on VLAN 100 and 200.
+func testPackets() []*Packet {
+	frames := [][]byte{
+		// PADI
+		ethernet(clientMAC, broadcast, []uint16{100}, etherTypePPPoEDiscovery, pppoe(0x09, 0, 0, nil)),
+		// PADO
+		ethernet(serverMAC, clientMAC, []uint16{100}, etherTypePPPoEDiscovery, pppoe(0x07, 0, 0, nil)),
+		// LCP configure request
+		ethernet(clientMAC, serverMAC, []uint16{100}, etherTypePPPoESession, pppoe(0, 1, pppLCP, []byte{1, 1, 0, 4})),
+		// IPCP configure request
+		ethernet(clientMAC, serverMAC, []uint16{100}, etherTypePPPoESession, pppoe(0, 1, pppIPCP, []byte{1, 1, 0, 4})),
+		// IPv4 in PPPoE session
+		ethernet(clientMAC, serverMAC, []uint16{100}, etherTypePPPoESession, pppoe(0, 1, pppIPv4, ipv4(clientIPv4, serverIPv4, ipProtocolUDP, udp(1000, 2000, []byte("data"))))),
+		// DHCP discover
+		ethernet(clientMAC, broadcast, []uint16{200, 1}, etherTypeIPv4, ipv4([]byte{0, 0, 0, 0}, []byte{255, 255, 255, 255}, ipProtocolUDP, udp(portDHCPClient, portDHCPServer, make([]byte, 240)))),
+		// ARP
+		ethernet(serverMAC, broadcast, []uint16{200, 1}, etherTypeARP, make([]byte, 28)),
+		// IGMP report
+		ethernet(clientMAC, serverMAC, []uint16{200, 1}, etherTypeIPv4, ipv4(clientIPv4, []byte{239, 0, 0, 1}, ipProtocolIGMP, make([]byte, 8))),
+		// ICMPv6 router advertisement
+		ethernet(serverMAC, clientMAC, nil, etherTypeIPv6, ipv6(ipProtocolICMPv6, append([]byte{icmpv6RouterAdvertisement}, make([]byte, 15)...))),
+		// DHCPv6 solicit
+		ethernet(clientMAC, serverMAC, nil, etherTypeIPv6, ipv6(ipProtocolUDP, udp(portDHCPv6Client, portDHCPv6Server, []byte{1, 0, 0, 1}))),
+		// L2TP
+		ethernet(serverMAC, clientMAC, nil, etherTypeIPv4, ipv4(serverIPv4, clientIPv4, ipProtocolUDP, udp(portL2TP, portL2TP, make([]byte, 12)))),
+	}
+	packets := make([]*Packet, len(frames))
+	for i, frame := range frames {
+		packets[i] = &Packet{
+			Timestamp: testStart.Add(time.Duration(i) * time.Second),
+			LinkType:  LinkTypeEthernet,
+			Length:    len(frame),
+			Data:      frame,
+		}
+	}
+	return packets
+}
+
+// writePcap writes the packets as classic pcap file with microsecond timestamps.
+func writePcap(packets []*Packet) []byte {
+	var buf bytes.Buffer
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:4], magicMicroseconds)
+	binary.LittleEndian.PutUint16(header[4:6], 2)
+	binary.LittleEndian.PutUint16(header[6:8], 4)
+	binary.LittleEndian.PutUint32(header[16:20], 65535)
+	binary.LittleEndian.PutUint32(header[20:24], LinkTypeEthernet)
+	buf.Write(header)
+	for _, p := range packets {
+		record := make([]byte, 16)
+		binary.LittleEndian.PutUint32(record[0:4], uint32(p.Timestamp.Unix()))
+		binary.LittleEndian.PutUint32(record[4:8], uint32(p.Timestamp.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(record[8:12], uint32(len(p.Data)))
+		binary.LittleEndian.PutUint32(record[12:16], uint32(p.Length))
+		buf.Write(record)
+		buf.Write(p.Data)
+	}
+	return buf.Bytes()
+}
+
+// pcapngBlock builds a little endian pcapng block.
+func pcapngBlock(blockType uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	length := uint32(12 + len(body))
+	block := binary.LittleEndian.AppendUint32(nil, blockType)
+	block = binary.LittleEndian.AppendUint32(block, length)
+	block = append(block, body...)
+	return binary.LittleEndian.AppendUint32(block, length)
+}
+
+// writePcapng writes the packets as pcapng file with two interfaces, the
+// second one with nanosecond resolution. Odd packets use the second interface.
+func writePcapng(packets []*Packet) []byte {
+	var buf bytes.Buffer
+	shb := binary.LittleEndian.AppendUint32(nil, byteOrderMagic)
+	shb = binary.LittleEndian.AppendUint16(shb, 1)
+	shb = binary.LittleEndian.AppendUint16(shb, 0)
+	shb = binary.LittleEndian.AppendUint64(shb, 0xffffffffffffffff)
+	buf.Write(pcapngBlock(blockSectionHeader, shb))
+	for _, resolution := range []uint8{6, 9} {
+		idb := binary.LittleEndian.AppendUint16(nil, LinkTypeEthernet)
+		idb = binary.LittleEndian.AppendUint16(idb, 0)
+		idb = binary.LittleEndian.AppendUint32(idb, 0)
+		idb = binary.LittleEndian.AppendUint16(idb, optionTimestampResolution)
+		idb = binary.LittleEndian.AppendUint16(idb, 1)
+		idb = append(idb, resolution, 0, 0, 0)
+		idb = append(idb, 0, 0, 0, 0)
+		buf.Write(pcapngBlock(blockInterfaceDescription, idb))
+	}
+	for i, p := range packets {
+		id := uint32(i % 2)
+		units := uint64(p.Timestamp.UnixMicro())
+		if id == 1 {
+			units = uint64(p.Timestamp.UnixNano())
+		}
+		epb := binary.LittleEndian.AppendUint32(nil, id)
+		epb = binary.LittleEndian.AppendUint32(epb, uint32(units>>32))
+		epb = binary.LittleEndian.AppendUint32(epb, uint32(units))
+		epb = binary.LittleEndian.AppendUint32(epb, uint32(len(p.Data)))
+		epb = binary.LittleEndian.AppendUint32(epb, uint32(p.Length))
+		epb = append(epb, p.Data...)
+		buf.Write(pcapngBlock(blockEnhancedPacket, epb))
+	}
+	return buf.Bytes()
+}
+
+// readPackets reads all packets of a capture.
+func readPackets(t *testing.T, data []byte) (Format, []*Packet) {
+	t.Helper()
+	reader, err := NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	var packets []*Packet
+	for {
+		block, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if block.Packet != nil {
+			packets = append(packets, block.Packet)
+		}
+	}
+	return reader.Format(), packets
+}
+
+func TestReader(t *testing.T) {
+	want := testPackets()
+	tests := []struct {
+		name       string
+		data       []byte
+		wantFormat Format
+	}{
+		{name: "pcap", data: writePcap(want), wantFormat: FormatPcap},
+		{name: "pcapng", data: writePcapng(want), wantFormat: FormatPcapng},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, packets := readPackets(t, tt.data)
+			require.Equal(t, tt.wantFormat, format)
+			require.Len(t, packets, len(want))
+			for i, p := range packets {
+				require.Equal(t, want[i].Timestamp, p.Timestamp)
+				require.Equal(t, want[i].Data, p.Data)
+				require.Equal(t, want[i].Length, p.Length)
+			}
+		})
+	}
+}
+
+func TestReader_Truncated(t *testing.T) {
+	packets := testPackets()
+	for _, data := range [][]byte{writePcap(packets), writePcapng(packets)} {
+		// A capture that is still written ends with an incomplete packet.
+		_, got := readPackets(t, data[:len(data)-10])
+		require.Len(t, got, len(packets)-1)
+	}
+}
+
+func TestNewReader_Invalid(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("no capture file")))
+	require.True(t, errors.Is(err, ErrInvalidFormat))
+}
+
+func TestDecode(t *testing.T) {
+	packets := testPackets()
+	tests := []struct {
+		name          string
+		packet        *Packet
+		wantVLANs     []uint16
+		wantProtocols []string
+	}{
+		{name: "padi", packet: packets[0], wantVLANs: []uint16{100}, wantProtocols: []string{ProtocolPPPoEDiscovery}},
+		{name: "lcp", packet: packets[2], wantVLANs: []uint16{100}, wantProtocols: []string{ProtocolPPPoESession, ProtocolLCP}},
+		{name: "ipcp", packet: packets[3], wantVLANs: []uint16{100}, wantProtocols: []string{ProtocolPPPoESession, ProtocolIPCP}},
+		{name: "pppoe_ipv4", packet: packets[4], wantVLANs: []uint16{100}, wantProtocols: []string{ProtocolPPPoESession, ProtocolIPv4, ProtocolUDP}},
+		{name: "dhcp", packet: packets[5], wantVLANs: []uint16{200, 1}, wantProtocols: []string{ProtocolIPv4, ProtocolUDP, ProtocolDHCP}},
+		{name: "arp", packet: packets[6], wantVLANs: []uint16{200, 1}, wantProtocols: []string{ProtocolARP}},
+		{name: "igmp", packet: packets[7], wantVLANs: []uint16{200, 1}, wantProtocols: []string{ProtocolIPv4, ProtocolIGMP}},
+		{name: "icmpv6_ra", packet: packets[8], wantProtocols: []string{ProtocolIPv6, ProtocolICMPv6, ProtocolICMPv6RA}},
+		{name: "dhcpv6", packet: packets[9], wantProtocols: []string{ProtocolIPv6, ProtocolUDP, ProtocolDHCPv6}},
+		{name: "l2tp", packet: packets[10], wantProtocols: []string{ProtocolIPv4, ProtocolUDP, ProtocolL2TP}},
+		{name: "truncated", packet: &Packet{LinkType: LinkTypeEthernet, Data: packets[5].Data[:30]}, wantVLANs: []uint16{200, 1}, wantProtocols: []string{ProtocolIPv4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := Decode(tt.packet)
+			require.Equal(t, tt.wantVLANs, l.VLANs)
+			require.Equal(t, tt.wantProtocols, l.Protocols)
+		})
+	}
+}
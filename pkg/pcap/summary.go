@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package pcap
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Talker is a source address with its packet and byte counts.
+type Talker struct {
+	Address string `json:"address"`
+	Packets int    `json:"packets"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// Talkers are the top sources by packets, by MAC and IP address.
+type Talkers struct {
+	MAC []Talker `json:"mac"`
+	IP  []Talker `json:"ip"`
+}
+
+// Summary are the statistics of a capture.
+type Summary struct {
+	Packets int `json:"packets"`
+	// Bytes is the sum of the original packet lengths.
+	Bytes int64      `json:"bytes"`
+	First *time.Time `json:"first,omitempty"`
+	Last  *time.Time `json:"last,omitempty"`
+	// EtherTypes are the packets by ether type (after the VLAN tags), e.g. 0x8863.
+	EtherTypes map[string]int `json:"ether_types"`
+	// VLANs are the tagged packets by VLAN identifiers, outer and inner separated by a dot.
+	VLANs map[string]int `json:"vlans"`
+	// Protocols are the packets by protocol, a packet is counted for every protocol it contains.
+	Protocols  map[string]int `json:"protocols"`
+	TopTalkers Talkers        `json:"top_talkers"`
+}
+
+// Summarize reads all packets and returns the statistics of the capture
+// including the top n talkers.
+func Summarize(reader *Reader, n int) (*Summary, error) {
+	s := &Summary{
+		EtherTypes: map[string]int{},
+		VLANs:      map[string]int{},
+		Protocols:  map[string]int{},
+	}
+	macs := map[string]*Talker{}
+	ips := map[string]*Talker{}
+	for {
+		block, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		p := block.Packet
+		if p == nil {
+			continue
+		}
+		s.Packets++
+		s.Bytes += int64(p.Length)
+		if !p.Timestamp.IsZero() {
+			timestamp := p.Timestamp
+			if s.First == nil || timestamp.Before(*s.First) {
+				s.First = &timestamp
+			}
+			if s.Last == nil || timestamp.After(*s.Last) {
+				s.Last = &timestamp
+			}
+		}
+
+		l := Decode(p)
+		if l.SrcMAC == nil {
+			continue
+		}
+		s.EtherTypes[fmt.Sprintf("0x%04x", l.EtherType)]++
+		if len(l.VLANs) > 0 {
+			ids := make([]string, len(l.VLANs))
+			for i, vlan := range l.VLANs {
+				ids[i] = strconv.Itoa(int(vlan))
+			}
+			s.VLANs[strings.Join(ids, ".")]++
+		}
+		for _, protocol := range l.Protocols {
+			s.Protocols[protocol]++
+		}
+		count(macs, l.SrcMAC.String(), p.Length)
+		if l.SrcIP != nil {
+			count(ips, l.SrcIP.String(), p.Length)
+		}
+	}
+	s.TopTalkers = Talkers{MAC: top(macs, n), IP: top(ips, n)}
+	return s, nil
+}
+
+func count(talkers map[string]*Talker, address string, length int) {
+	t, ok := talkers[address]
+	if !ok {
+		t = &Talker{Address: address}
+		talkers[address] = t
+	}
+	t.Packets++
+	t.Bytes += int64(length)
+}
+
+// top returns the n talkers with the most packets.
+func top(talkers map[string]*Talker, n int) []Talker {
+	result := make([]Talker, 0, len(talkers))
+	for _, t := range talkers {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Packets != result[j].Packets {
+			return result[i].Packets > result[j].Packets
+		}
+		return result[i].Address < result[j].Address
+	})
+	if n >= 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package pcap
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	packets := testPackets()
+	reader, err := NewReader(bytes.NewReader(writePcapng(packets)))
+	require.NoError(t, err)
+	s, err := Summarize(reader, 2)
+	require.NoError(t, err)
+
+	require.Equal(t, 11, s.Packets)
+	require.Equal(t, testStart, *s.First)
+	require.Equal(t, testStart.Add(10*time.Second), *s.Last)
+	require.Equal(t, map[string]int{"0x8863": 2, "0x8864": 3, "0x0800": 3, "0x0806": 1, "0x86dd": 2}, s.EtherTypes)
+	require.Equal(t, map[string]int{"100": 5, "200.1": 3}, s.VLANs)
+	require.Equal(t, 2, s.Protocols[ProtocolPPPoEDiscovery])
+	require.Equal(t, 3, s.Protocols[ProtocolPPPoESession])
+	require.Equal(t, 1, s.Protocols[ProtocolLCP])
+	require.Equal(t, 1, s.Protocols[ProtocolDHCP])
+	require.Equal(t, 1, s.Protocols[ProtocolICMPv6RA])
+	require.Equal(t, 1, s.Protocols[ProtocolL2TP])
+
+	require.Len(t, s.TopTalkers.MAC, 2)
+	require.Equal(t, "02:00:00:00:00:01", s.TopTalkers.MAC[0].Address)
+	require.Equal(t, 7, s.TopTalkers.MAC[0].Packets)
+	require.Equal(t, "02:00:00:00:00:02", s.TopTalkers.MAC[1].Address)
+	require.Equal(t, 4, s.TopTalkers.MAC[1].Packets)
+	require.Equal(t, []Talker{
+		{Address: "10.0.0.1", Packets: 2, Bytes: int64(packets[4].Length + packets[7].Length)},
+		{Address: "fe80::1", Packets: 2, Bytes: int64(packets[8].Length + packets[9].Length)},
+	}, s.TopTalkers.IP)
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+const (
+	chartWidth  = 720
+	chartHeight = 240
+	// chartMargin leaves space for the axis labels.
+	chartMargin = 48
+	// maxChartSeries limits the lines per chart, e.g. for many interfaces.
+	maxChartSeries = 10
+)
+
+// chartColors are used for the lines of a chart.
+var chartColors = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+// Chart is a line chart rendered as inline SVG.
+type Chart struct {
+	Title  string
+	Width  int
+	Height int
+	// Plot area.
+	Left, Top, Right, Bottom int
+	YMax                     string
+	XStart, XEnd             string
+	Lines                    []Line
+	// Omitted is the number of series not shown.
+	Omitted int
+}
+
+// Line is one series of a chart.
+type Line struct {
+	Name   string
+	Color  string
+	Points string
+}
+
+// newChart scales the timeseries into the plot area.
+func newChart(title string, series []controller.Timeseries) Chart {
+	c := Chart{
+		Title:  title,
+		Width:  chartWidth,
+		Height: chartHeight,
+		Left:   chartMargin,
+		Top:    chartMargin / 4,
+		Right:  chartWidth - chartMargin/4,
+		Bottom: chartHeight - chartMargin/2,
+	}
+	if len(series) > maxChartSeries {
+		c.Omitted = len(series) - maxChartSeries
+		series = series[:maxChartSeries]
+	}
+
+	start, end := series[0].Points[0].Timestamp, series[0].Points[0].Timestamp
+	yMax := 0.0
+	for _, s := range series {
+		for _, p := range s.Points {
+			if p.Timestamp.Before(start) {
+				start = p.Timestamp
+			}
+			if p.Timestamp.After(end) {
+				end = p.Timestamp
+			}
+			if p.Value > yMax {
+				yMax = p.Value
+			}
+		}
+	}
+	if yMax == 0 {
+		yMax = 1
+	}
+	duration := end.Sub(start).Seconds()
+	if duration == 0 {
+		duration = 1
+	}
+	c.YMax = formatValue(yMax)
+	c.XStart = start.Format("15:04:05")
+	c.XEnd = end.Format("15:04:05")
+
+	width := float64(c.Right - c.Left)
+	height := float64(c.Bottom - c.Top)
+	for i, s := range series {
+		points := make([]string, 0, len(s.Points))
+		for _, p := range s.Points {
+			x := float64(c.Left) + p.Timestamp.Sub(start).Seconds()/duration*width
+			y := float64(c.Bottom) - p.Value/yMax*height
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+		}
+		c.Lines = append(c.Lines, Line{
+			Name:   s.Metric,
+			Color:  chartColors[i%len(chartColors)],
+			Points: strings.Join(points, " "),
+		})
+	}
+	return c
+}
+
+// PlotWidth is the width of the plot area.
+func (c Chart) PlotWidth() int {
+	return c.Right - c.Left
+}
+
+// PlotHeight is the height of the plot area.
+func (c Chart) PlotHeight() int {
+	return c.Bottom - c.Top
+}
@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+// streamIdentifiers are the numeric stream fields that are not compared.
+var streamIdentifiers = map[string]bool{
+	"flow-id":    true,
+	"session-id": true,
+}
+
+// Delta is the difference of one metric between run a and b.
+type Delta struct {
+	Metric string  `json:"metric"`
+	A      float64 `json:"a"`
+	B      float64 `json:"b"`
+	Delta  float64 `json:"delta"`
+	// ChangePercent is the change relative to a, nil if a is zero.
+	ChangePercent *float64 `json:"change_percent"`
+}
+
+// StreamDelta are the differences of all streams with the same name.
+type StreamDelta struct {
+	Name    string  `json:"name"`
+	Metrics []Delta `json:"metrics"`
+}
+
+// ConfigDifference is a configuration value that differs between run a and b.
+// The value is missing (null) if the path exists in one configuration only.
+type ConfigDifference struct {
+	Path string      `json:"path"`
+	A    interface{} `json:"a"`
+	B    interface{} `json:"b"`
+}
+
+// Comparison is the result of comparing two runs.
+type Comparison struct {
+	A       string             `json:"a"`
+	B       string             `json:"b"`
+	Metrics []Delta            `json:"metrics"`
+	Streams []StreamDelta      `json:"streams"`
+	Config  []ConfigDifference `json:"config"`
+}
+
+// Compare compares the run reports and configurations of the runs in the folders a and b.
+// It returns an error satisfying os.IsNotExist if a run report is missing.
+func Compare(folderA string, nameA string, folderB string, nameB string) (*Comparison, error) {
+	reportA, err := readJSON(filepath.Join(folderA, controller.RunReportFilename))
+	if err != nil {
+		return nil, err
+	}
+	reportB, err := readJSON(filepath.Join(folderB, controller.RunReportFilename))
+	if err != nil {
+		return nil, err
+	}
+	// The configuration is optional, e.g. if it was deleted after the run.
+	configA, _ := readJSON(filepath.Join(folderA, controller.ConfigFilename))
+	configB, _ := readJSON(filepath.Join(folderB, controller.ConfigFilename))
+
+	summaryA, _ := reportA["report"].(map[string]interface{})
+	summaryB, _ := reportB["report"].(map[string]interface{})
+	c := &Comparison{
+		A:       nameA,
+		B:       nameB,
+		Metrics: deltas(numericFields(summaryA, nil), numericFields(summaryB, nil)),
+		Streams: []StreamDelta{},
+		Config:  []ConfigDifference{},
+	}
+
+	streamsA := streamsByName(reportStreams(reportA))
+	streamsB := streamsByName(reportStreams(reportB))
+	for _, name := range unionKeys(streamsA, streamsB) {
+		c.Streams = append(c.Streams, StreamDelta{
+			Name:    name,
+			Metrics: deltas(streamsA[name], streamsB[name]),
+		})
+	}
+
+	leavesA := map[string]interface{}{}
+	flattenConfig(leavesA, "", configA)
+	leavesB := map[string]interface{}{}
+	flattenConfig(leavesB, "", configB)
+	for _, path := range unionKeys(leavesA, leavesB) {
+		if !reflect.DeepEqual(leavesA[path], leavesB[path]) {
+			c.Config = append(c.Config, ConfigDifference{Path: path, A: leavesA[path], B: leavesB[path]})
+		}
+	}
+	return c, nil
+}
+
+func readJSON(file string) (map[string]interface{}, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(file), err)
+	}
+	return v, nil
+}
+
+// reportStreams returns the streams of a run report.
+func reportStreams(report map[string]interface{}) []interface{} {
+	if streams, ok := report["streams"].([]interface{}); ok {
+		return streams
+	}
+	summary, _ := report["report"].(map[string]interface{})
+	streams, _ := summary["streams"].([]interface{})
+	return streams
+}
+
+// streamLossPercent is the rx loss relative to the transmitted packets of all
+// streams with the same name.
+const streamLossPercent = "rx-loss-percent"
+
+// streamRateUnits are the units of rate fields, e.g. tx-pps or rx-bps-l2.
+var streamRateUnits = map[string]bool{
+	"pps":  true,
+	"bps":  true,
+	"kbps": true,
+	"mbps": true,
+	"gbps": true,
+}
+
+// sum adds up the values of the streams with the same name.
+func sum(a float64, b float64) float64 {
+	return a + b
+}
+
+// streamAggregation returns how the values of a stream field (the last part of
+// the metric name) are combined for all streams with the same name, nil if the
+// values are averaged.
+func streamAggregation(field string) func(a float64, b float64) float64 {
+	switch {
+	case strings.HasSuffix(field, "-min"):
+		return math.Min
+	case strings.HasSuffix(field, "-max"):
+		return math.Max
+	case strings.HasSuffix(field, "-packets"), strings.HasSuffix(field, "-bytes"),
+		strings.HasSuffix(field, "-loss"), strings.Contains(field, "-packets-"):
+		return sum
+	}
+	// The throughput of the streams with the same name is the sum of their rates.
+	for _, unit := range strings.Split(field, "-") {
+		if streamRateUnits[unit] {
+			return sum
+		}
+	}
+	// Lengths, configured values and the like are not additive.
+	return nil
+}
+
+// streamsByName combines the numeric fields of all streams with the same name.
+// Counters and rates are summed up, minima and maxima are kept and the other
+// fields are averaged over the streams having them. The loss percentage is
+// recomputed from the summed counters.
+func streamsByName(streams []interface{}) map[string]map[string]float64 {
+	result := map[string]map[string]float64{}
+	// count is the number of streams per name and metric.
+	count := map[string]map[string]float64{}
+	for _, s := range streams {
+		object, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := fmt.Sprint(object["name"])
+		values := result[name]
+		if values == nil {
+			values = map[string]float64{}
+			result[name] = values
+			count[name] = map[string]float64{}
+		}
+		for metric, value := range numericFields(object, nil) {
+			field := metric[strings.LastIndex(metric, ".")+1:]
+			if strings.Contains(field, "percent") {
+				continue
+			}
+			current, exists := values[metric]
+			count[name][metric]++
+			switch aggregate := streamAggregation(field); {
+			case !exists:
+				values[metric] = value
+			case aggregate == nil:
+				values[metric] += value
+			default:
+				values[metric] = aggregate(current, value)
+			}
+		}
+	}
+	for name, values := range result {
+		for metric := range values {
+			if streamAggregation(metric[strings.LastIndex(metric, ".")+1:]) == nil {
+				values[metric] /= count[name][metric]
+			}
+		}
+		if tx := values["tx-packets"]; tx > 0 {
+			values[streamLossPercent] = values["rx-loss"] / tx * 100
+		}
+	}
+	return result
+}
+
+// numericFields adds the numbers of the object and its nested objects
+// (named parent.child) to values. Lists and identifiers are skipped.
+func numericFields(object map[string]interface{}, values map[string]float64) map[string]float64 {
+	if values == nil {
+		values = map[string]float64{}
+	}
+	var walk func(prefix string, object map[string]interface{})
+	walk = func(prefix string, object map[string]interface{}) {
+		for key, value := range object {
+			switch value := value.(type) {
+			case map[string]interface{}:
+				walk(prefix+key+".", value)
+			case float64:
+				if !streamIdentifiers[key] {
+					values[prefix+key] += value
+				}
+			}
+		}
+	}
+	walk("", object)
+	return values
+}
+
+// deltas compares the metrics of a and b, metrics missing in one run are zero.
+func deltas(a map[string]float64, b map[string]float64) []Delta {
+	result := []Delta{}
+	for _, metric := range unionKeys(a, b) {
+		d := Delta{Metric: metric, A: a[metric], B: b[metric], Delta: b[metric] - a[metric]}
+		if d.A != 0 {
+			change := d.Delta / d.A * 100
+			d.ChangePercent = &change
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// flattenConfig adds all leaf values of v to leaves, named by their JSON path
+// (e.g. interfaces.access.0.interface).
+func flattenConfig(leaves map[string]interface{}, prefix string, v interface{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			flattenConfig(leaves, join(key), value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			flattenConfig(leaves, join(strconv.Itoa(i)), value)
+		}
+	case nil:
+		if prefix != "" {
+			leaves[prefix] = nil
+		}
+	default:
+		leaves[prefix] = v
+	}
+}
+
+// unionKeys returns the sorted keys of both maps.
+func unionKeys[V any](a map[string]V, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
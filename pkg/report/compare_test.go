@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package report
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+func TestCompare(t *testing.T) {
+	a := writeFiles(t, map[string]string{
+		controller.ConfigFilename: `{"interfaces": {"access": [{"interface": "eth1", "outer-vlan": 100}]}, "sessions": {"count": 10}}`,
+		controller.RunReportFilename: `{"report": {
+			"sessions-established": 10, "setup-rate": 100,
+			"streams": [
+				{"flow-id": 1, "name": "S1", "tx-packets": 1000, "rx-packets": 1000, "rx-loss": 0},
+				{"flow-id": 2, "name": "S1", "tx-packets": 1000, "rx-packets": 1000, "rx-loss": 0}
+			]
+		}}`,
+	})
+	b := writeFiles(t, map[string]string{
+		controller.ConfigFilename: `{"interfaces": {"access": [{"interface": "eth2", "outer-vlan": 100}]}, "sessions": {"count": 10, "start-rate": 50}}`,
+		controller.RunReportFilename: `{"report": {
+			"sessions-established": 8, "setup-rate": 80, "setup-time": 1000,
+			"streams": [
+				{"flow-id": 1, "name": "S1", "tx-packets": 1000, "rx-packets": 990, "rx-loss": 10},
+				{"flow-id": 2, "name": "S1", "tx-packets": 1000, "rx-packets": 1000, "rx-loss": 0},
+				{"flow-id": 3, "name": "S2", "tx-packets": 500, "rx-packets": 500, "rx-loss": 0}
+			]
+		}}`,
+	})
+
+	c, err := Compare(a, "a", b, "b")
+	require.NoError(t, err)
+	require.Equal(t, "a", c.A)
+
+	change := float64(-20)
+	require.Equal(t, []Delta{
+		{Metric: "sessions-established", A: 10, B: 8, Delta: -2, ChangePercent: &change},
+		{Metric: "setup-rate", A: 100, B: 80, Delta: -20, ChangePercent: &change},
+		{Metric: "setup-time", A: 0, B: 1000, Delta: 1000},
+	}, c.Metrics)
+
+	require.Len(t, c.Streams, 2)
+	require.Equal(t, "S1", c.Streams[0].Name)
+	require.Contains(t, c.Streams[0].Metrics, Delta{Metric: "rx-loss", A: 0, B: 10, Delta: 10})
+	for _, d := range c.Streams[0].Metrics {
+		require.NotEqual(t, "flow-id", d.Metric)
+	}
+	require.Equal(t, "S2", c.Streams[1].Name)
+
+	require.Equal(t, []ConfigDifference{
+		{Path: "interfaces.access.0.interface", A: "eth1", B: "eth2"},
+		{Path: "sessions.start-rate", A: nil, B: float64(50)},
+	}, c.Config)
+}
+
+func TestCompare_StreamsWithSameName(t *testing.T) {
+	a := writeFiles(t, map[string]string{
+		controller.RunReportFilename: `{"report": {"streams": [
+			{"flow-id": 1, "name": "S1", "tx-packets": 1000, "rx-packets": 1000, "rx-loss": 0,
+			 "rx-delay-nsec-min": 10, "rx-delay-nsec-max": 100, "rx-len": 128, "tx-len": 100, "tx-pps": 100, "rx-bps-l2": 1000},
+			{"flow-id": 2, "name": "S1", "tx-packets": 3000, "rx-packets": 2980, "rx-loss": 20,
+			 "rx-delay-nsec-min": 20, "rx-delay-nsec-max": 300, "rx-len": 256, "tx-pps": 300, "rx-bps-l2": 3000,
+			 "min-value": 5}
+		]}}`,
+	})
+	b := writeFiles(t, map[string]string{controller.RunReportFilename: `{"report": {}}`})
+
+	c, err := Compare(a, "a", b, "b")
+	require.NoError(t, err)
+	require.Len(t, c.Streams, 1)
+	got := map[string]float64{}
+	for _, d := range c.Streams[0].Metrics {
+		got[d.Metric] = d.A
+	}
+	require.Equal(t, map[string]float64{
+		"tx-packets":        4000,
+		"rx-packets":        3980,
+		"rx-loss":           20,
+		"rx-loss-percent":   0.5,
+		"rx-delay-nsec-min": 10,
+		"rx-delay-nsec-max": 300,
+		"rx-len":            192,
+		"tx-len":            100,
+		"tx-pps":            400,
+		"rx-bps-l2":         4000,
+		"min-value":         5,
+	}, got)
+}
+
+func TestCompare_MissingReport(t *testing.T) {
+	a := writeFiles(t, map[string]string{controller.RunReportFilename: `{"report": {}}`})
+	b := writeFiles(t, nil)
+	_, err := Compare(a, "a", b, "b")
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+
+// Package report renders a self-contained HTML report of a bngblaster run.
+package report
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var tmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(v float64) string { return fmt.Sprintf("%.3f %%", v) },
+}).Parse(reportTemplate))
+
+// chartPatterns are the recorded timeseries shown as chart.
+var chartPatterns = []struct {
+	title   string
+	pattern string
+}{
+	{title: "Established sessions", pattern: "session-counters.sessions-established"},
+	{title: "Session setup rate", pattern: "session-counters.setup-rate"},
+	{title: "Interface rx pps", pattern: "interfaces.*.rx-pps"},
+	{title: "Interface tx pps", pattern: "interfaces.*.tx-pps"},
+	{title: "Stream rx loss", pattern: "stream-summary.*.rx-loss"},
+}
+
+// Field is a name value pair of a table.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Stream is one row of the stream table.
+type Stream struct {
+	FlowID    string
+	Name      string
+	Direction string
+	TxPackets float64
+	RxPackets float64
+	RxLoss    float64
+	// LossPercent is the rx loss relative to the transmitted packets.
+	LossPercent float64
+}
+
+// Data is the content of the report.
+type Data struct {
+	Instance      string
+	Generated     time.Time
+	Status        string
+	Summary       []Field
+	Sessions      []Field
+	Streams       []Stream
+	Charts        []Chart
+	RunningConfig string
+	Config        string
+}
+
+// Generate renders the report of the instance in the given folder.
+func Generate(w io.Writer, folder string, instance string, running bool) error {
+	data, err := Collect(folder, instance, running)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+// Collect reads the files of the instance folder into the report data.
+// Missing files are skipped, the report contains what is available.
+func Collect(folder string, instance string, running bool) (*Data, error) {
+	if _, err := os.Stat(folder); err != nil {
+		return nil, err
+	}
+	data := &Data{
+		Instance:  instance,
+		Generated: time.Now().UTC(),
+		Status:    "not started",
+	}
+	if running {
+		data.Status = "running"
+	} else if code, ok := controller.ReadExitCode(folder); ok {
+		data.Status = fmt.Sprintf("exited with code %d", code)
+	}
+	data.Summary = append(data.Summary, Field{Name: "Instance", Value: instance}, Field{Name: "Status", Value: data.Status})
+	if info, err := os.Stat(filepath.Join(folder, controller.RunConfigFilename)); err == nil {
+		data.Summary = append(data.Summary, Field{Name: "Started", Value: info.ModTime().UTC().Format(time.RFC3339)})
+	}
+
+	if b, err := os.ReadFile(filepath.Join(folder, controller.ConfigFilename)); err == nil {
+		data.Config = indent(b)
+	}
+	if b, err := os.ReadFile(filepath.Join(folder, controller.RunConfigFilename)); err == nil {
+		data.RunningConfig = indent(b)
+	}
+
+	var report map[string]interface{}
+	if b, err := os.ReadFile(filepath.Join(folder, controller.RunReportFilename)); err == nil {
+		if err := json.Unmarshal(b, &report); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", controller.RunReportFilename, err)
+		}
+	}
+	summary, _ := report["report"].(map[string]interface{})
+	data.Sessions = scalarFields(summary)
+	streams, ok := report["streams"].([]interface{})
+	if !ok {
+		streams, _ = summary["streams"].([]interface{})
+	}
+	data.Streams = streamRows(streams)
+
+	timeseries := filepath.Join(folder, controller.RunTimeseriesFilename)
+	for _, c := range chartPatterns {
+		series, err := controller.ReadTimeseries(timeseries, []string{c.pattern})
+		if err != nil || len(series) == 0 {
+			continue
+		}
+		data.Charts = append(data.Charts, newChart(c.title, series))
+	}
+	return data, nil
+}
+
+// indent pretty prints JSON, other content is returned as is.
+func indent(b []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return string(b)
+	}
+	pretty, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return string(b)
+	}
+	return string(pretty)
+}
+
+// scalarFields returns the numbers, strings and booleans of the object and its
+// nested objects (named parent.child) sorted by name. Lists are skipped.
+func scalarFields(object map[string]interface{}) []Field {
+	var fields []Field
+	var walk func(prefix string, object map[string]interface{})
+	walk = func(prefix string, object map[string]interface{}) {
+		for key, value := range object {
+			switch value := value.(type) {
+			case map[string]interface{}:
+				walk(prefix+key+".", value)
+			case []interface{}:
+			case float64:
+				fields = append(fields, Field{Name: prefix + key, Value: fmt.Sprintf("%g", value)})
+			default:
+				fields = append(fields, Field{Name: prefix + key, Value: fmt.Sprint(value)})
+			}
+		}
+	}
+	walk("", object)
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Name < fields[j].Name
+	})
+	return fields
+}
+
+// streamRows converts the streams of the report into table rows ordered by loss.
+func streamRows(streams []interface{}) []Stream {
+	var rows []Stream
+	for _, s := range streams {
+		object, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		number := func(key string) float64 {
+			v, _ := object[key].(float64)
+			return v
+		}
+		row := Stream{
+			FlowID:    fmt.Sprint(object["flow-id"]),
+			Name:      fmt.Sprint(object["name"]),
+			Direction: fmt.Sprint(object["direction"]),
+			TxPackets: number("tx-packets"),
+			RxPackets: number("rx-packets"),
+			RxLoss:    number("rx-loss"),
+		}
+		if row.TxPackets > 0 {
+			row.LossPercent = row.RxLoss / row.TxPackets * 100
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].RxLoss > rows[j].RxLoss
+	})
+	return rows
+}
+
+// formatValue formats an axis value.
+func formatValue(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	return s
+}
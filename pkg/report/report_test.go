@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+// writeFiles writes the instance files into a new folder.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	folder := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(folder, name), []byte(content), 0o644))
+	}
+	return folder
+}
+
+func TestCollect(t *testing.T) {
+	folder := writeFiles(t, map[string]string{
+		controller.ConfigFilename:    `{"interfaces": {"access": [{"interface": "eth1"}]}}`,
+		controller.RunConfigFilename: `{"report": true, "timeseries_interval": 1}`,
+		controller.RunExitFilename:   "0",
+		controller.RunReportFilename: `{"report": {
+			"sessions": 10, "sessions-established": 10, "setup-rate": 99.5,
+			"session-traffic": {"total-flows": 20},
+			"streams": [
+				{"flow-id": 1, "name": "S1", "direction": "upstream", "tx-packets": 1000, "rx-packets": 990, "rx-loss": 10},
+				{"flow-id": 2, "name": "S1", "direction": "downstream", "tx-packets": 1000, "rx-packets": 1000, "rx-loss": 0}
+			]
+		}}`,
+		controller.RunTimeseriesFilename: `{"timestamp": "2025-01-01T00:00:00Z", "values": {"session-counters.sessions-established": 0, "interfaces.eth1.rx-pps": 10}}
+{"timestamp": "2025-01-01T00:00:10Z", "values": {"session-counters.sessions-established": 10, "interfaces.eth1.rx-pps": 20}}
+`,
+	})
+
+	data, err := Collect(folder, "test", false)
+	require.NoError(t, err)
+	require.Equal(t, "exited with code 0", data.Status)
+	require.Contains(t, data.Sessions, Field{Name: "setup-rate", Value: "99.5"})
+	require.Contains(t, data.Sessions, Field{Name: "session-traffic.total-flows", Value: "20"})
+	require.Len(t, data.Streams, 2)
+	require.Equal(t, "1", data.Streams[0].FlowID)
+	require.Equal(t, float64(1), data.Streams[0].LossPercent)
+	require.Len(t, data.Charts, 2)
+	require.Equal(t, "Established sessions", data.Charts[0].Title)
+	require.Len(t, data.Charts[0].Lines, 1)
+	// The first point is at the origin of the plot area, the last at the top right.
+	require.Equal(t, "48.0,216.0 708.0,12.0", data.Charts[0].Lines[0].Points)
+	require.Contains(t, data.RunningConfig, `"timeseries_interval": 1`)
+
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, folder, "test", false))
+	html := buf.String()
+	require.Contains(t, html, "<title>BNG Blaster Report - test</title>")
+	require.Contains(t, html, "<polyline")
+	require.Contains(t, html, "1.000 %")
+	require.NotContains(t, html, "ZgotmplZ")
+}
+
+func TestCollect_Empty(t *testing.T) {
+	folder := writeFiles(t, nil)
+	data, err := Collect(folder, "test", true)
+	require.NoError(t, err)
+	require.Equal(t, "running", data.Status)
+	require.Empty(t, data.Sessions)
+	require.Empty(t, data.Streams)
+	require.Empty(t, data.Charts)
+
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, folder, "test", true))
+	require.Contains(t, buf.String(), "No report available.")
+}
+
+func TestCollect_InvalidReport(t *testing.T) {
+	folder := writeFiles(t, map[string]string{controller.RunReportFilename: "{"})
+	_, err := Collect(folder, "test", false)
+	require.Error(t, err)
+}
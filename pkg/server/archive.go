@@ -0,0 +1,341 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+// manifestFilename is the name of the manifest in the root of an archive,
+// the instance files are stored in a folder named like the instance.
+const manifestFilename = "manifest.json"
+
+// maxImportSize is the maximum size of an imported archive, both compressed
+// and of all extracted files.
+const maxImportSize = 4000 << 20
+
+var (
+	// errInvalidArchive is returned for archives that can not be imported.
+	errInvalidArchive = errors.New("invalid archive")
+	// errArchiveTooLarge is returned if the extracted files exceed the limits.
+	errArchiveTooLarge = errors.New("archive too large")
+)
+
+// FileInfo describes an instance file, it is used for archive manifests and file listings.
+type FileInfo struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	SHA256   string    `json:"sha256"`
+	Modified time.Time `json:"modified"`
+}
+
+// manifest describes the content of an archive.
+type manifest struct {
+	Instance string     `json:"instance"`
+	Created  time.Time  `json:"created"`
+	Files    []FileInfo `json:"files"`
+}
+
+// archive streams a tar.gz of all files of an instance.
+func (s *Server) archive() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		if !s.repository.Exists(instance) {
+			JSONNotFound(w, r)
+			return
+		}
+		folder := filepath.Join(s.repository.ConfigFolder(), instance)
+
+		// Archives of large instances (e.g. with a run.pcap) take longer than
+		// the write timeout of the server.
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			log.Debug().Msgf("failed to clear the write deadline: %s", err.Error())
+		}
+		w.Header().Set(contentType, "application/gzip")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+instance+".tar.gz\"")
+		w.WriteHeader(http.StatusOK)
+		if err := writeArchive(w, folder, instance); err != nil {
+			// The status is already sent, the client gets a truncated archive.
+			log.Warn().Msgf("failed to archive %s: %s", instance, err.Error())
+		}
+	}
+}
+
+// writeArchive writes all regular files of the folder into a tar.gz with the
+// manifest as last entry.
+func writeArchive(w io.Writer, folder string, instance string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	m := manifest{Instance: instance, Created: time.Now().UTC(), Files: []FileInfo{}}
+	err := filepath.WalkDir(folder, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		name, err := filepath.Rel(folder, file)
+		if err != nil {
+			return err
+		}
+		f, err := archiveFile(tw, file, path.Join(instance, filepath.ToSlash(name)))
+		if err != nil {
+			return err
+		}
+		f.Name = filepath.ToSlash(name)
+		m.Files = append(m.Files, f)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     manifestFilename,
+		Size:     int64(len(b)),
+		Mode:     0o644,
+		ModTime:  m.Created,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(b); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// archiveFile adds the file to the archive and returns its manifest entry.
+func archiveFile(tw *tar.Writer, file string, name string) (FileInfo, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return FileInfo{}, err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     info.Size(),
+		Mode:     int64(info.Mode().Perm()),
+		ModTime:  info.ModTime(),
+	}); err != nil {
+		return FileInfo{}, err
+	}
+	// Files of a running instance grow, only the size at the time of the
+	// header is archived.
+	h := sha256.New()
+	n, err := io.CopyN(io.MultiWriter(tw, h), f, info.Size())
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("%s: %w (%d of %d bytes)", name, err, n, info.Size())
+	}
+	return FileInfo{
+		Size:     info.Size(),
+		SHA256:   hex.EncodeToString(h.Sum(nil)),
+		Modified: info.ModTime().UTC(),
+	}, nil
+}
+
+// importArchive creates or replaces an instance from a tar.gz archive. The archive is
+// either a download of the archive endpoint or contains the instance files in its root.
+func (s *Server) importArchive() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		if !s.repository.AllowUpload() {
+			JSONError(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if instance == "" {
+			JSONError(w, fmt.Sprintf("invalid instance %q", instanceVariable), http.StatusBadRequest)
+			return
+		}
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
+		if s.repository.Running(instance) {
+			JSONError(w, errInstanceIsRunning, http.StatusPreconditionFailed)
+			return
+		}
+
+		// Extract into a hidden folder of the config folder, so that the
+		// instance is replaced by a rename once the archive is verified.
+		tmp, err := os.MkdirTemp(s.repository.ConfigFolder(), ".import-")
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer os.RemoveAll(tmp)
+
+		folder, err := extractArchive(http.MaxBytesReader(w, r.Body, maxImportSize), tmp, s.maxUploadSize)
+		if err == nil {
+			// The uploaded files of the instance are limited by the quota.
+			err = s.quota.verify(folder)
+		}
+		var maxBytesError *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesError):
+			JSONError(w, errArchiveTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		case errors.Is(err, errArchiveTooLarge), errors.Is(err, errQuotaExceeded):
+			JSONError(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		case errors.Is(err, errInvalidArchive):
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		status := http.StatusCreated
+		if s.repository.Exists(instance) {
+			status = http.StatusNoContent
+		}
+		err = s.repository.Import(instance, folder)
+		if err == controller.ErrBlasterRunning {
+			JSONError(w, errInstanceIsRunning, http.StatusPreconditionFailed)
+			return
+		}
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(status)
+	}
+}
+
+// extractArchive extracts the tar.gz into tmp and returns the folder with the instance files.
+// Entries with paths leaving tmp and entries other than files and folders are rejected.
+// Files larger than maxFileSize (if not zero) are rejected as well as archives whose
+// files are larger than maxImportSize in total.
+func extractArchive(r io.Reader, tmp string, maxFileSize int64) (string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errInvalidArchive, err)
+	}
+	tr := tar.NewReader(gz)
+	// The tar reader returns no more than the size of the header of an entry.
+	var size int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", errInvalidArchive, err)
+		}
+		if !filepath.IsLocal(header.Name) {
+			return "", fmt.Errorf("%w: illegal path %q", errInvalidArchive, header.Name)
+		}
+		target := filepath.Join(tmp, filepath.FromSlash(header.Name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o777); err != nil {
+				return "", err
+			}
+		case tar.TypeReg:
+			if maxFileSize > 0 && header.Size > maxFileSize {
+				return "", fmt.Errorf("%w: %s is larger than %d bytes", errArchiveTooLarge, header.Name, maxFileSize)
+			}
+			if size += header.Size; size > maxImportSize {
+				return "", fmt.Errorf("%w: files are larger than %d bytes", errArchiveTooLarge, int64(maxImportSize))
+			}
+			if err := extractFile(tr, target); err != nil {
+				return "", err
+			}
+		default:
+			return "", fmt.Errorf("%w: %q is not a file or folder", errInvalidArchive, header.Name)
+		}
+	}
+
+	folder := tmp
+	if b, err := os.ReadFile(filepath.Join(tmp, manifestFilename)); err == nil {
+		var m manifest
+		if err := json.Unmarshal(b, &m); err != nil {
+			return "", fmt.Errorf("%w: %s: %s", errInvalidArchive, manifestFilename, err.Error())
+		}
+		if m.Instance == "" || cleanPathVariable(m.Instance) != m.Instance {
+			return "", fmt.Errorf("%w: illegal instance %q", errInvalidArchive, m.Instance)
+		}
+		folder = filepath.Join(tmp, m.Instance)
+		if err := verifyManifest(folder, m); err != nil {
+			return "", err
+		}
+	}
+	if _, err := os.Stat(filepath.Join(folder, controller.ConfigFilename)); err != nil {
+		return "", fmt.Errorf("%w: %s is missing", errInvalidArchive, controller.ConfigFilename)
+	}
+	return folder, nil
+}
+
+// extractFile writes the current entry of the archive to a new file.
+func extractFile(r io.Reader, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o777); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%w: duplicate file %q", errInvalidArchive, filepath.Base(target))
+		}
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("%w: %w", errInvalidArchive, err)
+	}
+	return f.Close()
+}
+
+// verifyManifest checks the size and checksum of all files listed in the manifest.
+func verifyManifest(folder string, m manifest) error {
+	for _, file := range m.Files {
+		if !filepath.IsLocal(file.Name) {
+			return fmt.Errorf("%w: illegal path %q", errInvalidArchive, file.Name)
+		}
+		f, err := os.Open(filepath.Join(folder, filepath.FromSlash(file.Name)))
+		if err != nil {
+			return fmt.Errorf("%w: %s is missing", errInvalidArchive, file.Name)
+		}
+		h := sha256.New()
+		n, err := io.Copy(h, f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		if n != file.Size || hex.EncodeToString(h.Sum(nil)) != file.SHA256 {
+			return fmt.Errorf("%w: checksum mismatch of %s", errInvalidArchive, file.Name)
+		}
+	}
+	return nil
+}
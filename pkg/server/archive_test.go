@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+// readArchive returns the files of a tar.gz archive.
+func readArchive(t *testing.T, b []byte) map[string][]byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := map[string][]byte{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = content
+	}
+}
+
+func TestServer_archive(t *testing.T) {
+	folder := t.TempDir()
+	files := map[string]string{
+		controller.ConfigFilename:    `{"interfaces": {}}`,
+		controller.RunLogFilename:    "[info] started\n",
+		controller.RunReportFilename: `{"report": {}}`,
+		"sessions.csv":               "1,2,3\n",
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "test"), 0o755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(folder, "test", name), []byte(content), 0o644))
+	}
+
+	tests := []struct {
+		name     string
+		instance string
+		want     int
+	}{
+		{name: "archive", instance: "test", want: http.StatusOK},
+		{name: "not_exists", instance: "missing", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return folder
+				},
+				ExistsFunc: func(name string) bool {
+					_, err := os.Stat(filepath.Join(folder, name))
+					return err == nil
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/instances/{instance_name}/_archive", tt.instance).
+				Expect().
+				Status(tt.want)
+			if tt.want != http.StatusOK {
+				return
+			}
+			response.ContentType("application/gzip")
+
+			archive := readArchive(t, []byte(response.Body().Raw()))
+			require.Len(t, archive, len(files)+1)
+			var m manifest
+			require.NoError(t, json.Unmarshal(archive[manifestFilename], &m))
+			require.Equal(t, "test", m.Instance)
+			require.Len(t, m.Files, len(files))
+			for _, f := range m.Files {
+				content := files[f.Name]
+				require.Equal(t, content, string(archive["test/"+f.Name]))
+				require.Equal(t, int64(len(content)), f.Size)
+				sum := sha256.Sum256([]byte(content))
+				require.Equal(t, hex.EncodeToString(sum[:]), f.SHA256)
+			}
+		})
+	}
+}
+
+// tarEntry is an entry of a test archive.
+type tarEntry struct {
+	name     string
+	content  string
+	typeflag byte
+}
+
+// makeArchive returns a tar.gz with the entries.
+func makeArchive(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		typeflag := e.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		header := &tar.Header{Typeflag: typeflag, Name: e.name, Mode: 0o644, Size: int64(len(e.content))}
+		if typeflag == tar.TypeSymlink {
+			header.Linkname, header.Size = e.content, 0
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		if typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(e.content))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestServer_importArchive(t *testing.T) {
+	source := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(source, controller.ConfigFilename), []byte(`{"streams": []}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "streams.json"), []byte(`{}`), 0o644))
+	var exported bytes.Buffer
+	require.NoError(t, writeArchive(&exported, source, "origin"))
+
+	tampered := makeArchive(t,
+		tarEntry{name: "origin/" + controller.ConfigFilename, content: `{"changed": true}`},
+		tarEntry{name: manifestFilename, content: `{"instance": "origin", "files": [{"name": "config.json", "size": 17, "sha256": "00"}]}`})
+
+	tests := []struct {
+		name      string
+		instance  string
+		body      []byte
+		upload    bool
+		running   bool
+		options   []Option
+		want      int
+		wantFiles map[string]string
+	}{
+		{
+			name: "exported", instance: "test", body: exported.Bytes(), upload: true, want: http.StatusCreated,
+			wantFiles: map[string]string{controller.ConfigFilename: `{"streams": []}`, "streams.json": `{}`},
+		},
+		{
+			name: "flat", instance: "test", upload: true, want: http.StatusCreated,
+			body: makeArchive(t,
+				tarEntry{name: controller.ConfigFilename, content: `{}`},
+				tarEntry{name: "aux", typeflag: tar.TypeDir},
+				tarEntry{name: "aux/streams.json", content: `[]`}),
+			wantFiles: map[string]string{controller.ConfigFilename: `{}`, "aux/streams.json": `[]`},
+		},
+		{name: "replace", instance: "existing", body: exported.Bytes(), upload: true, want: http.StatusNoContent},
+		{name: "traversal", instance: "test", upload: true, want: http.StatusBadRequest, body: makeArchive(t, tarEntry{name: "../evil", content: "x"})},
+		{name: "absolute", instance: "test", upload: true, want: http.StatusBadRequest, body: makeArchive(t, tarEntry{name: "/tmp/evil", content: "x"})},
+		{name: "symlink", instance: "test", upload: true, want: http.StatusBadRequest, body: makeArchive(t, tarEntry{name: "link", content: "/etc", typeflag: tar.TypeSymlink})},
+		{name: "duplicate", instance: "test", upload: true, want: http.StatusBadRequest, body: makeArchive(t, tarEntry{name: "a", content: "1"}, tarEntry{name: "./a", content: "2"})},
+		{name: "no_config", instance: "test", upload: true, want: http.StatusBadRequest, body: makeArchive(t, tarEntry{name: "streams.json", content: "{}"})},
+		{name: "checksum", instance: "test", upload: true, want: http.StatusBadRequest, body: tampered},
+		{name: "not_gzip", instance: "test", upload: true, want: http.StatusBadRequest, body: []byte("plain")},
+		{name: "file_too_large", instance: "test", body: exported.Bytes(), upload: true, options: []Option{WithMaxUploadSize(10)}, want: http.StatusRequestEntityTooLarge},
+		{name: "quota_bytes", instance: "test", body: exported.Bytes(), upload: true, options: []Option{WithFileQuota(1, 0)}, want: http.StatusRequestEntityTooLarge},
+		{
+			name: "quota_files", instance: "test", upload: true, options: []Option{WithFileQuota(0, 1)}, want: http.StatusRequestEntityTooLarge,
+			body: makeArchive(t,
+				tarEntry{name: controller.ConfigFilename, content: `{}`},
+				tarEntry{name: "a.json", content: `{}`},
+				tarEntry{name: "b.json", content: `{}`}),
+		},
+		{name: "empty_instance", instance: "...", body: exported.Bytes(), upload: true, want: http.StatusBadRequest},
+		{name: "upload_disabled", instance: "test", body: exported.Bytes(), want: http.StatusForbidden},
+		{name: "running", instance: "test", body: exported.Bytes(), upload: true, running: true, want: http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(folder, "existing"), 0o755))
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return folder
+				},
+				AllowUploadFunc: func() bool {
+					return tt.upload
+				},
+				RunningFunc: func(name string) bool {
+					return tt.running
+				},
+				ExistsFunc: func(name string) bool {
+					_, err := os.Stat(filepath.Join(folder, name))
+					return err == nil
+				},
+				ImportFunc: func(name string, source string) error {
+					require.NoError(t, os.RemoveAll(filepath.Join(folder, name)))
+					return os.Rename(source, filepath.Join(folder, name))
+				},
+			}
+
+			handler := NewServer(repository, tt.options...)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			e.POST("/api/v1/instances/{instance_name}/_import", tt.instance).
+				WithHeader("Content-Type", "application/gzip").
+				WithBytes(tt.body).
+				Expect().
+				Status(tt.want)
+
+			for name, content := range tt.wantFiles {
+				require.Equal(t, content, string(mustReadFile(t, filepath.Join(folder, tt.instance, name))))
+			}
+			// The temporary folders are removed.
+			entries, err := os.ReadDir(folder)
+			require.NoError(t, err)
+			for _, entry := range entries {
+				require.NotContains(t, entry.Name(), ".import-")
+			}
+		})
+	}
+}
+
+func mustReadFile(t *testing.T, file string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+	return b
+}
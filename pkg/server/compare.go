@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/report"
+)
+
+// lastRun is the only run kept per instance, the files of a run are
+// replaced when the instance is started again.
+const lastRun = "last"
+
+// compareParameter parses a run reference of the form instance[/run].
+func compareParameter(r *http.Request, parameter string) (instance string, err error) {
+	v := r.URL.Query().Get(parameter)
+	if v == "" {
+		return "", fmt.Errorf("missing parameter %q", parameter)
+	}
+	instanceVariable, run, _ := strings.Cut(v, "/")
+	if run != "" && run != lastRun {
+		return "", fmt.Errorf("run %q of %q not found, only the %s run of an instance is kept", run, instanceVariable, lastRun)
+	}
+	instance = cleanPathVariable(instanceVariable)
+	if instance == "" {
+		return "", fmt.Errorf("invalid instance %q", instanceVariable)
+	}
+	return instance, nil
+}
+
+// compare returns the differences of the run reports and configurations of two runs.
+func (s *Server) compare() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var names [2]string
+		for i, parameter := range []string{"a", "b"} {
+			instance, err := compareParameter(r, parameter)
+			if err != nil {
+				JSONError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if !s.repository.Exists(instance) {
+				JSONError(w, fmt.Sprintf("instance %q not found", instance), http.StatusNotFound)
+				return
+			}
+			names[i] = instance
+		}
+
+		folder := s.repository.ConfigFolder()
+		comparison, err := report.Compare(
+			filepath.Join(folder, names[0]), names[0],
+			filepath.Join(folder, names[1]), names[1])
+		if os.IsNotExist(err) {
+			JSONError(w, "run report not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(comparison)
+	}
+}
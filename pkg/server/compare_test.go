@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+func TestServer_compare(t *testing.T) {
+	folder := t.TempDir()
+	for name, report := range map[string]string{
+		"a":     `{"report": {"setup-rate": 100}}`,
+		"b":     `{"report": {"setup-rate": 50}}`,
+		"empty": "",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(folder, name), 0o755))
+		if report != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(folder, name, controller.RunReportFilename), []byte(report), 0o644))
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "compare", query: "a=a&b=b", want: http.StatusOK},
+		{name: "last_run", query: "a=a/last&b=b", want: http.StatusOK},
+		{name: "missing_parameter", query: "a=a", want: http.StatusBadRequest},
+		{name: "unknown_run", query: "a=a/1&b=b", want: http.StatusBadRequest},
+		{name: "empty_instance", query: "a=/last&b=b", want: http.StatusBadRequest},
+		{name: "dot_instance", query: "a=..&b=b", want: http.StatusBadRequest},
+		{name: "unknown_instance", query: "a=a&b=missing", want: http.StatusNotFound},
+		{name: "no_report", query: "a=a&b=empty", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return folder
+				},
+				ExistsFunc: func(name string) bool {
+					_, err := os.Stat(filepath.Join(folder, name))
+					return err == nil
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/compare").
+				WithQueryString(tt.query).
+				Expect().
+				Status(tt.want)
+			if tt.want != http.StatusOK {
+				return
+			}
+			metric := response.JSON().Object().Value("metrics").Array().Element(0).Object()
+			metric.ValueEqual("metric", "setup-rate")
+			metric.ValueEqual("delta", -50)
+			metric.ValueEqual("change_percent", -50)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	encodingGzip = "gzip"
+	encodingZstd = "zstd"
+
+	// minCompressSize is the size below which files are not worth compressing.
+	minCompressSize = 1024
+)
+
+// acceptEncoding returns the preferred supported content encoding of the
+// Accept-Encoding header or an empty string. zstd wins over gzip on equal quality.
+func acceptEncoding(r *http.Request) string {
+	best, bestQuality := "", 0.0
+	for _, header := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(header, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != encodingGzip && coding != encodingZstd {
+				continue
+			}
+			quality := 1.0
+			if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				q, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					continue
+				}
+				quality = q
+			}
+			if quality <= 0 {
+				continue
+			}
+			if quality > bestQuality || (quality == bestQuality && coding == encodingZstd) {
+				best, bestQuality = coding, quality
+			}
+		}
+	}
+	return best
+}
+
+// serveFile serves the file compressed with the encoding accepted by the client.
+// Range and conditional requests are served uncompressed by http.ServeFile, so
+// resumed downloads keep working with the offsets of the file on disk.
+func serveFile(w http.ResponseWriter, r *http.Request, file string) {
+	w.Header().Add("Vary", "Accept-Encoding")
+	encoding := acceptEncoding(r)
+	if encoding == "" || r.Header.Get("Range") != "" ||
+		r.Header.Get("If-Modified-Since") != "" || r.Header.Get("If-None-Match") != "" {
+		http.ServeFile(w, r, file)
+		return
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		http.ServeFile(w, r, file)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() || info.Size() < minCompressSize {
+		http.ServeFile(w, r, file)
+		return
+	}
+
+	ctype := mime.TypeByExtension(filepath.Ext(file))
+	if ctype == "" {
+		// Sniff the content type like http.ServeFile does.
+		buf := make([]byte, 512)
+		n, _ := io.ReadFull(f, buf)
+		ctype = http.DetectContentType(buf[:n])
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set(contentType, ctype)
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	var encoder io.WriteCloser
+	if encoding == encodingZstd {
+		encoder, err = zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+	} else {
+		encoder, err = gzip.NewWriterLevel(w, gzip.BestSpeed)
+	}
+	if err != nil {
+		log.Warn().Msgf("failed to create %s encoder: %s", encoding, err.Error())
+		return
+	}
+	if _, err := io.Copy(encoder, f); err != nil {
+		log.Warn().Msgf("failed to send %s: %s", filepath.Base(file), err.Error())
+	}
+	if err := encoder.Close(); err != nil {
+		log.Warn().Msgf("failed to send %s: %s", filepath.Base(file), err.Error())
+	}
+}
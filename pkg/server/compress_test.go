@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+func TestAcceptEncoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "none", header: "", want: ""},
+		{name: "gzip", header: "gzip, deflate", want: encodingGzip},
+		{name: "zstd_preferred", header: "gzip, zstd", want: encodingZstd},
+		{name: "quality", header: "zstd;q=0.5, gzip;q=0.8", want: encodingGzip},
+		{name: "disabled", header: "gzip;q=0", want: ""},
+		{name: "unsupported", header: "br", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Encoding", tt.header)
+			require.Equal(t, tt.want, acceptEncoding(r))
+		})
+	}
+}
+
+func TestServer_fileServingCompressed(t *testing.T) {
+	folder := t.TempDir()
+	content := strings.Repeat("[info] session 1 established\n", 100)
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "test"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "test", controller.RunLogFilename), []byte(content), 0o644))
+
+	tests := []struct {
+		name         string
+		headers      map[string]string
+		want         int
+		wantEncoding string
+		wantBody     string
+	}{
+		{name: "identity", want: http.StatusOK, wantBody: content},
+		{name: "gzip", headers: map[string]string{"Accept-Encoding": "gzip"}, want: http.StatusOK, wantEncoding: encodingGzip, wantBody: content},
+		{name: "zstd", headers: map[string]string{"Accept-Encoding": "zstd"}, want: http.StatusOK, wantEncoding: encodingZstd, wantBody: content},
+		{name: "range", headers: map[string]string{"Accept-Encoding": "gzip", "Range": "bytes=29-"}, want: http.StatusPartialContent, wantBody: content[29:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return folder
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			request := e.GET("/api/v1/instances/{instance_name}/run.log", "test")
+			if tt.headers["Accept-Encoding"] == "" {
+				// Prevent the transparent compression of the client.
+				request = request.WithHeader("Accept-Encoding", "identity")
+			}
+			for k, v := range tt.headers {
+				request = request.WithHeader(k, v)
+			}
+			response := request.Expect().Status(tt.want)
+			response.Header("Content-Encoding").Equal(tt.wantEncoding)
+
+			body := []byte(response.Body().Raw())
+			var reader io.Reader = bytes.NewReader(body)
+			switch tt.wantEncoding {
+			case encodingGzip:
+				gz, err := gzip.NewReader(reader)
+				require.NoError(t, err)
+				reader = gz
+			case encodingZstd:
+				zr, err := zstd.NewReader(reader)
+				require.NoError(t, err)
+				defer zr.Close()
+				reader = zr
+			}
+			got, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBody, string(got))
+		})
+	}
+}
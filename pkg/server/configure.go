@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"syscall"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/netlink"
+)
+
+// linkConfigurer changes the settings of network interfaces.
+type linkConfigurer interface {
+	SetUp(name string, up bool) error
+	SetMTU(name string, mtu int) error
+	SetPromisc(name string, promisc bool) error
+	SetRings(name string, rx uint32, tx uint32) error
+}
+
+// netlinkConfigurer changes the settings of network interfaces with netlink.
+type netlinkConfigurer struct{}
+
+func (netlinkConfigurer) SetUp(name string, up bool) error {
+	return netlink.SetLinkUp(name, up)
+}
+
+func (netlinkConfigurer) SetMTU(name string, mtu int) error {
+	return netlink.SetLinkMTU(name, mtu)
+}
+
+func (netlinkConfigurer) SetPromisc(name string, promisc bool) error {
+	return netlink.SetLinkPromisc(name, promisc)
+}
+
+func (netlinkConfigurer) SetRings(name string, rx uint32, tx uint32) error {
+	return netlink.SetRingSizes(name, rx, tx)
+}
+
+// InterfaceConfig are the settings of a network interface to change,
+// settings which are not specified are not changed.
+type InterfaceConfig struct {
+	// Up sets the interface administratively up or down
+	Up *bool `json:"up,omitempty"`
+	// MTU of the interface
+	MTU *int `json:"mtu,omitempty"`
+	// Promisc enables or disables the promiscuous mode
+	Promisc *bool `json:"promisc,omitempty"`
+	// RxRing is the size of the rx ring
+	RxRing *uint32 `json:"rx_ring,omitempty"`
+	// TxRing is the size of the tx ring
+	TxRing *uint32 `json:"tx_ring,omitempty"`
+}
+
+// validate checks that the config changes anything and the values are valid.
+func (c InterfaceConfig) validate() error {
+	if c.Up == nil && c.MTU == nil && c.Promisc == nil && c.RxRing == nil && c.TxRing == nil {
+		return fmt.Errorf("no settings to change")
+	}
+	if c.MTU != nil && (*c.MTU < 68 || *c.MTU > 65535) {
+		return fmt.Errorf("mtu must be between 68 and 65535")
+	}
+	if (c.RxRing != nil && *c.RxRing == 0) || (c.TxRing != nil && *c.TxRing == 0) {
+		return fmt.Errorf("ring sizes must not be 0")
+	}
+	return nil
+}
+
+// configurable returns true if the interface is on the allow-list.
+func (s *Server) configurable(name string) bool {
+	for _, pattern := range s.configurableInterfaces {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// apply changes the settings of the interface. An interface is set down
+// before and up after the other settings are changed.
+func (c InterfaceConfig) apply(links linkConfigurer, name string) error {
+	if c.Up != nil && !*c.Up {
+		if err := links.SetUp(name, false); err != nil {
+			return fmt.Errorf("link down: %w", err)
+		}
+	}
+	if c.MTU != nil {
+		if err := links.SetMTU(name, *c.MTU); err != nil {
+			return fmt.Errorf("mtu: %w", err)
+		}
+	}
+	if c.RxRing != nil || c.TxRing != nil {
+		var rx, tx uint32
+		if c.RxRing != nil {
+			rx = *c.RxRing
+		}
+		if c.TxRing != nil {
+			tx = *c.TxRing
+		}
+		if err := links.SetRings(name, rx, tx); err != nil {
+			return fmt.Errorf("ring sizes: %w", err)
+		}
+	}
+	if c.Promisc != nil {
+		if err := links.SetPromisc(name, *c.Promisc); err != nil {
+			return fmt.Errorf("promiscuous mode: %w", err)
+		}
+	}
+	if c.Up != nil && *c.Up {
+		if err := links.SetUp(name, true); err != nil {
+			return fmt.Errorf("link up: %w", err)
+		}
+	}
+	return nil
+}
+
+// configureInterface changes the settings of an interface of the allow-list,
+// which is not used by a running instance.
+func (s *Server) configureInterface() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)[interfaceNameParameter]
+		if !s.configurable(name) {
+			JSONError(w, fmt.Sprintf("interface %s is not configurable", name), http.StatusForbidden)
+			return
+		}
+		var config InterfaceConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := config.validate(); err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		iface, err := net.InterfaceByName(name)
+		if err != nil {
+			JSONNotFound(w, r)
+			return
+		}
+		if instance, ok := s.claimedInterfaces()[name]; ok {
+			JSONError(w, fmt.Sprintf("interface %s used by running instance %s", name, instance), http.StatusConflict)
+			return
+		}
+		if !s.checkLease(w, r, "", []string{name}) {
+			return
+		}
+
+		log.Info().Str("interface", name).Interface("config", config).Msg("configure interface")
+		if err := config.apply(s.links, name); err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, netlink.ErrNotSupported):
+				status = http.StatusNotImplemented
+			case errors.Is(err, syscall.EINVAL), errors.Is(err, syscall.ERANGE), errors.Is(err, syscall.EOPNOTSUPP):
+				status = http.StatusBadRequest
+			}
+			JSONError(w, err.Error(), status)
+			return
+		}
+
+		// Read the interface again for the changed flags and MTU.
+		if changed, err := net.InterfaceByName(name); err == nil {
+			iface = changed
+		}
+		info := interfaceInfo(s.sysfs, *iface)
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(info)
+	}
+}
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+	"github.com/rtbrick/bngblaster-controller/pkg/netlink"
+)
+
+// fakeLinks records the changes instead of changing the interfaces.
+type fakeLinks struct {
+	calls []string
+	err   error
+}
+
+func (f *fakeLinks) SetUp(name string, up bool) error {
+	f.calls = append(f.calls, fmt.Sprintf("%s up %t", name, up))
+	return f.err
+}
+
+func (f *fakeLinks) SetMTU(name string, mtu int) error {
+	f.calls = append(f.calls, fmt.Sprintf("%s mtu %d", name, mtu))
+	return f.err
+}
+
+func (f *fakeLinks) SetPromisc(name string, promisc bool) error {
+	f.calls = append(f.calls, fmt.Sprintf("%s promisc %t", name, promisc))
+	return f.err
+}
+
+func (f *fakeLinks) SetRings(name string, rx uint32, tx uint32) error {
+	f.calls = append(f.calls, fmt.Sprintf("%s rings %d %d", name, rx, tx))
+	return f.err
+}
+
+func TestServer_configureInterface(t *testing.T) {
+	tests := []struct {
+		name      string
+		iface     string
+		body      interface{}
+		running   bool
+		leased    bool
+		linkErr   error
+		want      int
+		wantCalls []string
+	}{
+		{
+			name: "configure", iface: "lo", want: http.StatusOK,
+			body:      map[string]interface{}{"up": true, "mtu": 9000, "promisc": true, "rx_ring": 4096},
+			wantCalls: []string{"lo mtu 9000", "lo rings 4096 0", "lo promisc true", "lo up true"},
+		},
+		{
+			name: "down", iface: "lo", want: http.StatusOK,
+			body:      map[string]interface{}{"up": false, "mtu": 1500},
+			wantCalls: []string{"lo up false", "lo mtu 1500"},
+		},
+		{name: "not_allowed", iface: "eth0", body: map[string]interface{}{"up": true}, want: http.StatusForbidden},
+		{name: "no_settings", iface: "lo", body: map[string]interface{}{}, want: http.StatusBadRequest},
+		{name: "invalid_mtu", iface: "lo", body: map[string]interface{}{"mtu": 10}, want: http.StatusBadRequest},
+		{name: "invalid_ring", iface: "lo", body: map[string]interface{}{"tx_ring": 0}, want: http.StatusBadRequest},
+		{name: "bad_body", iface: "lo", body: "up", want: http.StatusBadRequest},
+		{name: "not_exists", iface: "missing0", body: map[string]interface{}{"up": true}, want: http.StatusNotFound},
+		{name: "running", iface: "lo", running: true, body: map[string]interface{}{"up": true}, want: http.StatusConflict},
+		{name: "leased", iface: "lo", leased: true, body: map[string]interface{}{"up": true}, want: http.StatusLocked},
+		{
+			name: "invalid_value", iface: "lo", linkErr: syscall.EINVAL, want: http.StatusBadRequest,
+			body: map[string]interface{}{"rx_ring": 100000}, wantCalls: []string{"lo rings 100000 0"},
+		},
+		{
+			name: "not_supported", iface: "lo", linkErr: netlink.ErrNotSupported, want: http.StatusNotImplemented,
+			body: map[string]interface{}{"up": true}, wantCalls: []string{"lo up true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(folder, "test"), 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(folder, "test", controller.ConfigFilename),
+				[]byte(`{"interfaces": {"network": {"interface": "lo"}}}`), 0o644))
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return folder
+				},
+				InstancesFunc: func() []string {
+					return []string{"test"}
+				},
+				RunningFunc: func(name string) bool {
+					return tt.running
+				},
+			}
+			links := &fakeLinks{err: tt.linkErr}
+			s := NewServer(repository, WithConfigurableInterfaces("lo", "missing*"))
+			s.links = links
+			s.sysfs = t.TempDir()
+			if tt.leased {
+				_, err := s.leases.Acquire(controller.Lease{Holder: "ci", Interfaces: []string{"lo"}})
+				require.NoError(t, err)
+			}
+			server := httptest.NewServer(s)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.POST("/api/v1/interfaces/{interface_name}/_configure", tt.iface).
+				WithJSON(tt.body).
+				Expect().
+				Status(tt.want)
+			if tt.want == http.StatusOK {
+				response.JSON().Object().ValueEqual("name", tt.iface)
+			}
+			require.Equal(t, tt.wantCalls, links.calls)
+		})
+	}
+}
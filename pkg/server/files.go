@@ -0,0 +1,406 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+const (
+	fileNameParameter = "file_name"
+
+	// contentSHA256 is the optional header with the hex encoded sha256 of an uploaded file.
+	contentSHA256 = "Content-SHA256"
+
+	// DefaultMaxUploadSize is the default maximum size of an uploaded file.
+	DefaultMaxUploadSize = 4000 << 20
+)
+
+// validChecksum is a hex encoded sha256.
+var validChecksum = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// validFilename are the names allowed for uploaded files, a
+// leading dot is reserved for temporary files of the controller.
+var validFilename = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._+-]{0,254}$`)
+
+var (
+	errInvalidFilename = errors.New("invalid file name")
+	errReservedFile    = errors.New("reserved file name")
+	errQuotaExceeded   = errors.New("file quota exceeded")
+)
+
+// checkFilename returns errInvalidFilename or errReservedFile if the
+// name can not be used for an uploaded file.
+func checkFilename(name string) error {
+	if !validFilename.MatchString(name) {
+		return fmt.Errorf("%w %q", errInvalidFilename, name)
+	}
+	if controller.IsRunFile(name) {
+		return fmt.Errorf("%w %q", errReservedFile, name)
+	}
+	return nil
+}
+
+// filenameStatus returns the HTTP status for the errors of checkFilename.
+func filenameStatus(err error) int {
+	if errors.Is(err, errReservedFile) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
+// uploadedFiles returns the uploaded files of the instance folder by name.
+func uploadedFiles(folder string) (map[string]os.FileInfo, error) {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]os.FileInfo{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || checkFilename(entry.Name()) != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files[entry.Name()] = info
+	}
+	return files, nil
+}
+
+// quota limits the size and number of uploaded files in a folder, zero means unlimited.
+type quota struct {
+	bytes int64
+	files int
+}
+
+// check returns errQuotaExceeded if a file with name and size does not
+// fit into the quota of the folder. An existing file with the same name
+// is replaced and does not count.
+func (q quota) check(folder string, name string, size int64) error {
+	if q.bytes <= 0 && q.files <= 0 {
+		return nil
+	}
+	files, err := uploadedFiles(folder)
+	if err != nil {
+		return err
+	}
+	delete(files, name)
+	return q.exceeded(files, size, 1)
+}
+
+// verify returns errQuotaExceeded if the uploaded files of the folder exceed the quota.
+func (q quota) verify(folder string) error {
+	if q.bytes <= 0 && q.files <= 0 {
+		return nil
+	}
+	files, err := uploadedFiles(folder)
+	if err != nil {
+		return err
+	}
+	return q.exceeded(files, 0, 0)
+}
+
+// exceeded returns errQuotaExceeded if the files together with the
+// additional files of size bytes exceed the quota.
+func (q quota) exceeded(files map[string]os.FileInfo, size int64, additional int) error {
+	used := size
+	for _, info := range files {
+		used += info.Size()
+	}
+	if q.bytes > 0 && used > q.bytes {
+		return fmt.Errorf("%w: %d of %d bytes", errQuotaExceeded, used, q.bytes)
+	}
+	if q.files > 0 && len(files)+additional > q.files {
+		return fmt.Errorf("%w: more than %d files", errQuotaExceeded, q.files)
+	}
+	return nil
+}
+
+// fileFolder returns the folder of the files addressed by a request
+// and false if the folder (e.g. the instance) does not exist.
+type fileFolder func(r *http.Request) (string, bool)
+
+// instanceFolder is the fileFolder of the files uploaded to an instance.
+// An empty instance name (e.g. "...") would address the config folder.
+func (s *Server) instanceFolder(r *http.Request) (string, bool) {
+	instanceVariable := mux.Vars(r)[instanceNameParameter]
+	instance := cleanPathVariable(instanceVariable)
+	return filepath.Join(s.repository.ConfigFolder(), instance), instance != "" && s.repository.Exists(instance)
+}
+
+// libraryFolder is the fileFolder of the shared file library, which
+// is created on the first upload.
+func (s *Server) libraryFolder(_ *http.Request) (string, bool) {
+	return s.repository.LibraryFolder(), true
+}
+
+// checksumEntry is a cached checksum, valid as long as size and modification time match.
+type checksumEntry struct {
+	size     int64
+	modified time.Time
+	sha256   string
+}
+
+// checksumCache avoids hashing large files for every listing.
+type checksumCache struct {
+	mu      sync.Mutex
+	entries map[string]checksumEntry
+}
+
+func newChecksumCache() *checksumCache {
+	return &checksumCache{entries: map[string]checksumEntry{}}
+}
+
+// fileInfo returns the info of the file including its checksum.
+func (c *checksumCache) fileInfo(file string, info os.FileInfo) (FileInfo, error) {
+	fi := FileInfo{Name: info.Name(), Size: info.Size(), Modified: info.ModTime().UTC()}
+	c.mu.Lock()
+	entry, ok := c.entries[file]
+	c.mu.Unlock()
+	if ok && entry.size == info.Size() && entry.modified.Equal(info.ModTime()) {
+		fi.SHA256 = entry.sha256
+		return fi, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return fi, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fi, err
+	}
+	fi.SHA256 = hex.EncodeToString(h.Sum(nil))
+	c.set(file, info, fi.SHA256)
+	return fi, nil
+}
+
+func (c *checksumCache) set(file string, info os.FileInfo, sum string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[file] = checksumEntry{size: info.Size(), modified: info.ModTime(), sha256: sum}
+}
+
+func (c *checksumCache) remove(file string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, file)
+}
+
+// files lists the uploaded files of a folder.
+func (s *Server) files(folderOf fileFolder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		folder, ok := folderOf(r)
+		if !ok {
+			JSONNotFound(w, r)
+			return
+		}
+		files, err := uploadedFiles(folder)
+		if err != nil && !os.IsNotExist(err) {
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		list := make([]FileInfo, 0, len(files))
+		for name, info := range files {
+			fi, err := s.checksums.fileInfo(filepath.Join(folder, name), info)
+			if err != nil {
+				// Deleted while listing.
+				continue
+			}
+			list = append(list, fi)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(list)
+	}
+}
+
+// file downloads an uploaded file.
+func (s *Server) file(folderOf fileFolder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		folder, ok := folderOf(r)
+		if !ok {
+			JSONNotFound(w, r)
+			return
+		}
+		name := mux.Vars(r)[fileNameParameter]
+		if err := checkFilename(name); err != nil {
+			JSONError(w, err.Error(), filenameStatus(err))
+			return
+		}
+		file := filepath.Join(folder, name)
+		if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+			JSONNotFound(w, r)
+			return
+		}
+		serveFile(w, r, file)
+	}
+}
+
+// putFile creates or replaces an uploaded file with the request body.
+func (s *Server) putFile(folderOf fileFolder, q quota) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)[fileNameParameter]
+		if !s.repository.AllowUpload() {
+			JSONError(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		folder, ok := folderOf(r)
+		if !ok {
+			JSONNotFound(w, r)
+			return
+		}
+		// The files of the library are not leased.
+		if !s.checkLease(w, r, cleanPathVariable(mux.Vars(r)[instanceNameParameter]), nil) {
+			return
+		}
+		if err := checkFilename(name); err != nil {
+			JSONError(w, err.Error(), filenameStatus(err))
+			return
+		}
+		if err := os.MkdirAll(folder, 0o777); err != nil {
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		info, status, err := s.storeFile(folder, name, r.Body, r.ContentLength, r.Header.Get(contentSHA256), q)
+		if err != nil {
+			JSONError(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(info)
+	}
+}
+
+// storeFile writes the content into a temporary file, which replaces the file
+// name if it is complete, matches the checksum (if not empty) and does not exceed
+// the maximum size and the quota of the folder. The size is -1 if unknown. It returns
+// http.StatusCreated or http.StatusOK on success and the error status otherwise.
+func (s *Server) storeFile(folder string, name string, content io.Reader, size int64, checksum string, q quota) (FileInfo, int, error) {
+	checksum = strings.ToLower(checksum)
+	if checksum != "" && !validChecksum.MatchString(checksum) {
+		return FileInfo{}, http.StatusBadRequest, fmt.Errorf("invalid %s %q", contentSHA256, checksum)
+	}
+	if s.maxUploadSize > 0 && size > s.maxUploadSize {
+		return FileInfo{}, http.StatusRequestEntityTooLarge, fmt.Errorf("file too large (> %d bytes)", s.maxUploadSize)
+	}
+	if err := q.check(folder, name, max(size, 0)); errors.Is(err, errQuotaExceeded) {
+		return FileInfo{}, http.StatusRequestEntityTooLarge, err
+	}
+	// Stop reading as soon as the maximum size or the quota is exceeded.
+	limit := s.maxUploadSize
+	if q.bytes > 0 && (limit <= 0 || q.bytes < limit) {
+		limit = q.bytes
+	}
+	if limit > 0 {
+		content = io.LimitReader(content, limit+1)
+	}
+
+	tmp, err := os.CreateTemp(folder, ".upload-")
+	if err != nil {
+		return FileInfo{}, http.StatusInternalServerError, err
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	s.metrics.Uploaded(n)
+	if err != nil {
+		return FileInfo{}, http.StatusInternalServerError, err
+	}
+	if s.maxUploadSize > 0 && n > s.maxUploadSize {
+		return FileInfo{}, http.StatusRequestEntityTooLarge, fmt.Errorf("file too large (> %d bytes)", s.maxUploadSize)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if checksum != "" && checksum != sum {
+		return FileInfo{}, http.StatusBadRequest, fmt.Errorf("checksum mismatch, received %s", sum)
+	}
+
+	// The quota is checked again when the size is known and the
+	// lock prevents concurrent uploads from exceeding it.
+	s.filesMu.Lock()
+	defer s.filesMu.Unlock()
+	if err := q.check(folder, name, n); err != nil {
+		if errors.Is(err, errQuotaExceeded) {
+			return FileInfo{}, http.StatusRequestEntityTooLarge, err
+		}
+		return FileInfo{}, http.StatusInternalServerError, err
+	}
+	file := filepath.Join(folder, name)
+	status := http.StatusCreated
+	if _, err := os.Stat(file); err == nil {
+		status = http.StatusOK
+	}
+	if err := os.Chmod(tmp.Name(), 0o666); err != nil {
+		return FileInfo{}, http.StatusInternalServerError, err
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return FileInfo{}, http.StatusInternalServerError, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return FileInfo{}, http.StatusInternalServerError, err
+	}
+	s.checksums.set(file, info, sum)
+	return FileInfo{Name: name, Size: n, SHA256: sum, Modified: info.ModTime().UTC()}, status, nil
+}
+
+// deleteFile deletes an uploaded file.
+func (s *Server) deleteFile(folderOf fileFolder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)[fileNameParameter]
+		if !s.repository.AllowUpload() {
+			JSONError(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		folder, ok := folderOf(r)
+		if !ok {
+			JSONNotFound(w, r)
+			return
+		}
+		if err := checkFilename(name); err != nil {
+			JSONError(w, err.Error(), filenameStatus(err))
+			return
+		}
+		// The files of the library are not leased.
+		if !s.checkLease(w, r, cleanPathVariable(mux.Vars(r)[instanceNameParameter]), nil) {
+			return
+		}
+		file := filepath.Join(folder, name)
+		if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+			JSONNotFound(w, r)
+			return
+		}
+		if err := os.Remove(file); err != nil {
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.checksums.remove(file)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
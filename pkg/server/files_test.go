@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// filesRepository returns a repository mock for the instance folders of folder.
+func filesRepository(folder string, upload bool) *controller.RepositoryMock {
+	return &controller.RepositoryMock{
+		ConfigFolderFunc: func() string {
+			return folder
+		},
+		AllowUploadFunc: func() bool {
+			return upload
+		},
+		ExistsFunc: func(name string) bool {
+			_, err := os.Stat(filepath.Join(folder, name))
+			return err == nil
+		},
+	}
+}
+
+func TestCheckFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     int
+	}{
+		{name: "valid", filename: "streams.json", want: 0},
+		{name: "valid_mrt", filename: "isis_1-2.mrt", want: 0},
+		{name: "hidden", filename: ".upload-1", want: http.StatusBadRequest},
+		{name: "option", filename: "-rf", want: http.StatusBadRequest},
+		{name: "traversal", filename: "..", want: http.StatusBadRequest},
+		{name: "slash", filename: "a/b", want: http.StatusBadRequest},
+		{name: "space", filename: "a b", want: http.StatusBadRequest},
+		{name: "empty", filename: "", want: http.StatusBadRequest},
+		{name: "config", filename: controller.ConfigFilename, want: http.StatusForbidden},
+		{name: "pid", filename: "run.pid", want: http.StatusForbidden},
+		{name: "report", filename: controller.RunReportFilename, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFilename(tt.filename)
+			if tt.want == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tt.want, filenameStatus(err))
+		})
+	}
+}
+
+func TestServer_files(t *testing.T) {
+	folder := t.TempDir()
+	instance := filepath.Join(folder, "test")
+	require.NoError(t, os.MkdirAll(instance, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(instance, controller.ConfigFilename), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(instance, controller.RunLogFilename), []byte("log"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(instance, "streams.json"), []byte("[]"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(instance, "isis.mrt"), []byte("mrt"), 0o644))
+
+	server := httptest.NewServer(NewServer(filesRepository(folder, true)))
+	defer server.Close()
+	e := httpexpect.New(t, server.URL)
+
+	files := e.GET("/api/v1/instances/{instance_name}/files", "test").
+		Expect().
+		Status(http.StatusOK).
+		JSON().Array()
+	files.Length().Equal(2)
+	files.Element(0).Object().ValueEqual("name", "isis.mrt")
+	files.Element(0).Object().ValueEqual("size", 3)
+	files.Element(0).Object().ValueEqual("sha256", sha256Hex("mrt"))
+	files.Element(1).Object().ValueEqual("name", "streams.json")
+
+	e.GET("/api/v1/instances/{instance_name}/files", "missing").
+		Expect().
+		Status(http.StatusNotFound)
+
+	// The empty instance name must not address the config folder.
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "root.json"), []byte("{}"), 0o644))
+	e.GET("/api/v1/instances/{instance_name}/files", "...").Expect().Status(http.StatusNotFound)
+	e.GET("/api/v1/instances/{instance_name}/files/{file_name}", "...", "root.json").Expect().Status(http.StatusNotFound)
+	e.PUT("/api/v1/instances/{instance_name}/files/{file_name}", "...", "root.json").WithText("[]").Expect().Status(http.StatusNotFound)
+	e.DELETE("/api/v1/instances/{instance_name}/files/{file_name}", "...", "root.json").Expect().Status(http.StatusNotFound)
+	require.Equal(t, "{}", string(mustReadFile(t, filepath.Join(folder, "root.json"))))
+}
+
+func TestServer_file(t *testing.T) {
+	folder := t.TempDir()
+	instance := filepath.Join(folder, "test")
+	require.NoError(t, os.MkdirAll(instance, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(instance, controller.ConfigFilename), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(instance, "streams.json"), []byte("[]"), 0o644))
+
+	tests := []struct {
+		name     string
+		filename string
+		want     int
+	}{
+		{name: "exists", filename: "streams.json", want: http.StatusOK},
+		{name: "not_exists", filename: "isis.mrt", want: http.StatusNotFound},
+		{name: "reserved", filename: controller.ConfigFilename, want: http.StatusForbidden},
+		{name: "invalid", filename: ".hidden", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(NewServer(filesRepository(folder, true)))
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/instances/{instance_name}/files/{file_name}", "test", tt.filename).
+				Expect().
+				Status(tt.want)
+			if tt.want == http.StatusOK {
+				response.Body().Equal("[]")
+			}
+		})
+	}
+}
+
+func TestServer_putFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		instance  string
+		filename  string
+		content   string
+		upload    bool
+		quota     int64
+		limit     int
+		checksum  string
+		want      int
+		wantFiles int
+	}{
+		{name: "create", instance: "test", filename: "new.json", content: "{}", upload: true, want: http.StatusCreated, wantFiles: 2},
+		{name: "replace", instance: "test", filename: "streams.json", content: "{}", upload: true, want: http.StatusOK, wantFiles: 1},
+		{name: "replace_within_quota", instance: "test", filename: "streams.json", content: "12345678", upload: true, quota: 8, limit: 1, want: http.StatusOK, wantFiles: 1},
+		{name: "quota", instance: "test", filename: "new.json", content: "12345678", upload: true, quota: 8, want: http.StatusRequestEntityTooLarge, wantFiles: 1},
+		{name: "limit", instance: "test", filename: "new.json", content: "{}", upload: true, limit: 1, want: http.StatusRequestEntityTooLarge, wantFiles: 1},
+		{name: "checksum", instance: "test", filename: "new.json", content: "{}", upload: true, checksum: sha256Hex("{}"), want: http.StatusCreated, wantFiles: 2},
+		{name: "checksum_mismatch", instance: "test", filename: "new.json", content: "{}", upload: true, checksum: sha256Hex("[]"), want: http.StatusBadRequest, wantFiles: 1},
+		{name: "reserved", instance: "test", filename: controller.ConfigFilename, content: "{}", upload: true, want: http.StatusForbidden, wantFiles: 1},
+		{name: "invalid", instance: "test", filename: "-rf", content: "{}", upload: true, want: http.StatusBadRequest, wantFiles: 1},
+		{name: "upload_disabled", instance: "test", filename: "new.json", content: "{}", want: http.StatusForbidden, wantFiles: 1},
+		{name: "not_exists", instance: "missing", filename: "new.json", content: "{}", upload: true, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			instance := filepath.Join(folder, "test")
+			require.NoError(t, os.MkdirAll(instance, 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(instance, controller.ConfigFilename), []byte("{}"), 0o644))
+			require.NoError(t, os.WriteFile(filepath.Join(instance, "streams.json"), []byte("[]"), 0o644))
+
+			server := httptest.NewServer(NewServer(filesRepository(folder, tt.upload), WithFileQuota(tt.quota, tt.limit)))
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.PUT("/api/v1/instances/{instance_name}/files/{file_name}", tt.instance, tt.filename).
+				WithText(tt.content).
+				WithHeader("Content-SHA256", tt.checksum).
+				Expect().
+				Status(tt.want)
+			if tt.want == http.StatusOK || tt.want == http.StatusCreated {
+				response.JSON().Object().ValueEqual("sha256", sha256Hex(tt.content))
+				require.Equal(t, tt.content, string(mustReadFile(t, filepath.Join(instance, tt.filename))))
+			}
+			if tt.instance != "test" {
+				return
+			}
+			files, err := uploadedFiles(instance)
+			require.NoError(t, err)
+			require.Len(t, files, tt.wantFiles)
+			// No temporary files are left.
+			entries, err := os.ReadDir(instance)
+			require.NoError(t, err)
+			require.Len(t, entries, tt.wantFiles+1)
+		})
+	}
+}
+
+func TestServer_deleteFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		upload   bool
+		want     int
+	}{
+		{name: "delete", filename: "streams.json", upload: true, want: http.StatusNoContent},
+		{name: "not_exists", filename: "isis.mrt", upload: true, want: http.StatusNotFound},
+		{name: "reserved", filename: controller.ConfigFilename, upload: true, want: http.StatusForbidden},
+		{name: "upload_disabled", filename: "streams.json", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			instance := filepath.Join(folder, "test")
+			require.NoError(t, os.MkdirAll(instance, 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(instance, controller.ConfigFilename), []byte("{}"), 0o644))
+			require.NoError(t, os.WriteFile(filepath.Join(instance, "streams.json"), []byte("[]"), 0o644))
+
+			server := httptest.NewServer(NewServer(filesRepository(folder, tt.upload)))
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			e.DELETE("/api/v1/instances/{instance_name}/files/{file_name}", "test", tt.filename).
+				Expect().
+				Status(tt.want)
+			_, err := os.Stat(filepath.Join(instance, "streams.json"))
+			require.Equal(t, tt.want == http.StatusNoContent, os.IsNotExist(err))
+		})
+	}
+}
+
+// multipartBody returns a multipart form with the file and its content type.
+func multipartBody(t *testing.T, field string, filename string, content string) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	require.NoError(t, mw.WriteField("comment", "before the file"))
+	part, err := mw.CreateFormFile(field, filename)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	return buf.Bytes(), mw.FormDataContentType()
+}
+
+func TestServer_uploadFile(t *testing.T) {
+	const content = "mrt dump"
+	tests := []struct {
+		name     string
+		field    string
+		filename string
+		checksum string
+		upload   bool
+		maxSize  int64
+		want     int
+	}{
+		{name: "upload", field: "file", filename: "isis.mrt", upload: true, want: http.StatusOK},
+		{name: "checksum", field: "file", filename: "isis.mrt", checksum: strings.ToUpper(sha256Hex(content)), upload: true, want: http.StatusOK},
+		{name: "checksum_mismatch", field: "file", filename: "isis.mrt", checksum: sha256Hex("other"), upload: true, want: http.StatusBadRequest},
+		{name: "checksum_invalid", field: "file", filename: "isis.mrt", checksum: "md5", upload: true, want: http.StatusBadRequest},
+		{name: "max_size", field: "file", filename: "isis.mrt", upload: true, maxSize: 4, want: http.StatusRequestEntityTooLarge},
+		{name: "no_file", field: "other", filename: "isis.mrt", upload: true, want: http.StatusBadRequest},
+		// The directory of the file name is ignored.
+		{name: "directory", field: "file", filename: "../isis.mrt", upload: true, want: http.StatusOK},
+		{name: "hidden", field: "file", filename: ".isis.mrt", upload: true, want: http.StatusBadRequest},
+		{name: "reserved", field: "file", filename: controller.RunPcapFilename, upload: true, want: http.StatusForbidden},
+		{name: "upload_disabled", field: "file", filename: "isis.mrt", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			instance := filepath.Join(folder, "test")
+			require.NoError(t, os.MkdirAll(instance, 0o755))
+
+			server := httptest.NewServer(NewServer(filesRepository(folder, tt.upload), WithMaxUploadSize(tt.maxSize)))
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			body, contentType := multipartBody(t, tt.field, tt.filename, content)
+			request := e.POST("/api/v1/instances/{instance_name}/_upload", "test").
+				WithHeader("Content-Type", contentType).
+				WithBytes(body)
+			if tt.checksum != "" {
+				request = request.WithHeader("Content-SHA256", tt.checksum)
+			}
+			request.Expect().Status(tt.want)
+
+			entries, err := os.ReadDir(instance)
+			require.NoError(t, err)
+			if tt.want != http.StatusOK {
+				// Nothing is stored, not even a temporary file.
+				require.Empty(t, entries)
+				return
+			}
+			require.Len(t, entries, 1)
+			require.Equal(t, content, string(mustReadFile(t, filepath.Join(instance, "isis.mrt"))))
+		})
+	}
+}
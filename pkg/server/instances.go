@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+// labelRequirement is one requirement of a label selector.
+type labelRequirement struct {
+	name     string
+	operator string
+	value    string
+}
+
+// matches returns true if the labels fulfill the requirement.
+func (l labelRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[l.name]
+	switch l.operator {
+	case "=":
+		return ok && value == l.value
+	case "!=":
+		return !ok || value != l.value
+	case "!":
+		return !ok
+	default:
+		return ok
+	}
+}
+
+// parseLabelSelector parses the comma separated label requirements
+// name=value, name!=value, name (label exists) and !name (label does not exist).
+func parseLabelSelector(selector string) ([]labelRequirement, error) {
+	var requirements []labelRequirement
+	for _, requirement := range strings.Split(selector, ",") {
+		l := labelRequirement{name: requirement}
+		if name, value, ok := strings.Cut(requirement, "!="); ok {
+			l = labelRequirement{name: name, operator: "!=", value: value}
+		} else if name, value, ok := strings.Cut(requirement, "="); ok {
+			l = labelRequirement{name: name, operator: "=", value: value}
+		} else if name, ok := strings.CutPrefix(requirement, "!"); ok {
+			l = labelRequirement{name: name, operator: "!"}
+		}
+		if err := controller.ValidateLabels(map[string]string{l.name: l.value}); err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", requirement, err)
+		}
+		requirements = append(requirements, l)
+	}
+	return requirements, nil
+}
+
+// instanceLess compares two instances by a field.
+type instanceLess func(a, b *controller.InstanceInfo) bool
+
+// lastRunStarted returns the start of the last run or the zero time.
+func lastRunStarted(info *controller.InstanceInfo) time.Time {
+	if info.LastRun == nil {
+		return time.Time{}
+	}
+	return info.LastRun.Started
+}
+
+// instanceSorting are the fields to sort instances by.
+var instanceSorting = map[string]instanceLess{
+	"name":     func(a, b *controller.InstanceInfo) bool { return a.Name < b.Name },
+	"owner":    func(a, b *controller.InstanceInfo) bool { return a.Owner < b.Owner },
+	"status":   func(a, b *controller.InstanceInfo) bool { return a.Status < b.Status },
+	"created":  func(a, b *controller.InstanceInfo) bool { return a.Created.Before(b.Created) },
+	"modified": func(a, b *controller.InstanceInfo) bool { return a.Modified.Before(b.Modified) },
+	"last_run": func(a, b *controller.InstanceInfo) bool { return lastRunStarted(a).Before(lastRunStarted(b)) },
+}
+
+// instances lists the instances with their metadata, optionally filtered by
+// label selectors, status and owner and sorted by a field (-field descending).
+func (s *Server) instances() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var selector []labelRequirement
+		for _, label := range query["label"] {
+			requirements, err := parseLabelSelector(label)
+			if err != nil {
+				JSONError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			selector = append(selector, requirements...)
+		}
+		status := query.Get("status")
+		if status != "" && status != controller.StatusStarted && status != controller.StatusStopped {
+			JSONError(w, fmt.Sprintf("invalid status %q", status), http.StatusBadRequest)
+			return
+		}
+		field := query.Get("sort")
+		if field == "" {
+			field = "name"
+		}
+		descending := strings.HasPrefix(field, "-")
+		less, ok := instanceSorting[strings.TrimPrefix(field, "-")]
+		if !ok {
+			JSONError(w, fmt.Sprintf("invalid sort field %q", field), http.StatusBadRequest)
+			return
+		}
+		_, filterOwner := query["owner"]
+		owner := query.Get("owner")
+
+		instances := []controller.InstanceInfo{}
+	next:
+		for _, name := range s.repository.Instances() {
+			info, err := s.repository.Info(name)
+			if err != nil {
+				// Deleted while listing.
+				continue
+			}
+			if status != "" && info.Status != status {
+				continue
+			}
+			if filterOwner && info.Owner != owner {
+				continue
+			}
+			for _, requirement := range selector {
+				if !requirement.matches(info.Labels) {
+					continue next
+				}
+			}
+			instances = append(instances, info)
+		}
+		sort.SliceStable(instances, func(i, j int) bool {
+			if descending {
+				return less(&instances[j], &instances[i])
+			}
+			return less(&instances[i], &instances[j])
+		})
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(instances)
+	}
+}
+
+// metadata returns the metadata, status and last run of an instance.
+func (s *Server) metadata() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		info, err := s.repository.Info(instance)
+		if err == controller.ErrBlasterNotExists {
+			JSONNotFound(w, r)
+			return
+		}
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(info)
+	}
+}
+
+// putMetadata replaces the owner, description and labels of an instance.
+func (s *Server) putMetadata() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
+		var metadata controller.Metadata
+		if err := json.NewDecoder(r.Body).Decode(&metadata); err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := controller.ValidateLabels(metadata.Labels); err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		err := s.repository.SetMetadata(instance, metadata)
+		if err == controller.ErrBlasterNotExists {
+			JSONNotFound(w, r)
+			return
+		}
+		if err != nil {
+			JSONError(w, "not able to update metadata", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
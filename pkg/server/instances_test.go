@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	labels := map[string]string{"team": "bng", "env": "lab"}
+	tests := []struct {
+		name     string
+		selector string
+		want     bool
+		wantErr  bool
+	}{
+		{name: "equal", selector: "team=bng", want: true},
+		{name: "equal_other", selector: "team=core", want: false},
+		{name: "not_equal", selector: "team!=core", want: true},
+		{name: "not_equal_missing", selector: "dut!=r1", want: true},
+		{name: "exists", selector: "env", want: true},
+		{name: "not_exists", selector: "!env", want: false},
+		{name: "and", selector: "team=bng,env=prod", want: false},
+		{name: "invalid", selector: "team=bng=core", wantErr: true},
+		{name: "empty", selector: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requirements, err := parseLabelSelector(tt.selector)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			got := true
+			for _, requirement := range requirements {
+				got = got && requirement.matches(labels)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServer_instances(t *testing.T) {
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	infos := map[string]controller.InstanceInfo{
+		"a": {Name: "a", Status: controller.StatusStopped, Metadata: controller.Metadata{
+			Owner: "alice", Labels: map[string]string{"team": "bng"}, Created: day.Add(2 * time.Hour),
+		}},
+		"b": {Name: "b", Status: controller.StatusStarted, Metadata: controller.Metadata{
+			Owner: "bob", Labels: map[string]string{"team": "core", "env": "lab"}, Created: day,
+		}},
+		"c": {Name: "c", Status: controller.StatusStopped, Metadata: controller.Metadata{
+			Created: day.Add(time.Hour),
+		}},
+	}
+	tests := []struct {
+		name  string
+		query map[string]string
+		want  int
+		names []string
+	}{
+		{name: "all", want: http.StatusOK, names: []string{"a", "b", "c"}},
+		{name: "label", query: map[string]string{"label": "team=bng"}, want: http.StatusOK, names: []string{"a"}},
+		{name: "label_not_equal", query: map[string]string{"label": "team!=bng"}, want: http.StatusOK, names: []string{"b", "c"}},
+		{name: "label_exists", query: map[string]string{"label": "env"}, want: http.StatusOK, names: []string{"b"}},
+		{name: "status", query: map[string]string{"status": "started"}, want: http.StatusOK, names: []string{"b"}},
+		{name: "owner", query: map[string]string{"owner": "alice"}, want: http.StatusOK, names: []string{"a"}},
+		{name: "no_owner", query: map[string]string{"owner": ""}, want: http.StatusOK, names: []string{"c"}},
+		{name: "sort_created", query: map[string]string{"sort": "created"}, want: http.StatusOK, names: []string{"b", "c", "a"}},
+		{name: "sort_descending", query: map[string]string{"sort": "-name"}, want: http.StatusOK, names: []string{"c", "b", "a"}},
+		{name: "invalid_label", query: map[string]string{"label": "team=a b"}, want: http.StatusBadRequest},
+		{name: "invalid_status", query: map[string]string{"status": "running"}, want: http.StatusBadRequest},
+		{name: "invalid_sort", query: map[string]string{"sort": "size"}, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return configFolder
+				},
+				InstancesFunc: func() []string {
+					return []string{"c", "a", "b", "deleted"}
+				},
+				InfoFunc: func(name string) (controller.InstanceInfo, error) {
+					info, ok := infos[name]
+					if !ok {
+						return info, controller.ErrBlasterNotExists
+					}
+					return info, nil
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			request := e.GET("/api/v1/instances")
+			for key, value := range tt.query {
+				request.WithQuery(key, value)
+			}
+			response := request.Expect().Status(tt.want)
+			if tt.want != http.StatusOK {
+				return
+			}
+			list := response.JSON().Array()
+			list.Length().Equal(len(tt.names))
+			for i, name := range tt.names {
+				list.Element(i).Object().ValueEqual("name", name)
+			}
+		})
+	}
+}
+
+func TestServer_metadata(t *testing.T) {
+	repository := &controller.RepositoryMock{
+		ConfigFolderFunc: func() string {
+			return configFolder
+		},
+		InfoFunc: func(name string) (controller.InstanceInfo, error) {
+			if name != "test" {
+				return controller.InstanceInfo{}, controller.ErrBlasterNotExists
+			}
+			code := 0
+			return controller.InstanceInfo{
+				Name:     name,
+				Status:   controller.StatusStopped,
+				Metadata: controller.Metadata{Owner: "alice", Labels: map[string]string{"team": "bng"}},
+				LastRun:  &controller.RunResult{ExitCode: &code},
+			}, nil
+		},
+	}
+	server := httptest.NewServer(NewServer(repository))
+	defer server.Close()
+	e := httpexpect.New(t, server.URL)
+
+	object := e.GET("/api/v1/instances/{instance_name}/metadata", "test").Expect().Status(http.StatusOK).JSON().Object()
+	object.ValueEqual("name", "test").ValueEqual("owner", "alice").ValueEqual("status", "stopped")
+	object.Value("labels").Object().ValueEqual("team", "bng")
+	object.Value("last_run").Object().ValueEqual("exit_code", 0)
+	e.GET("/api/v1/instances/{instance_name}/metadata", "missing").Expect().Status(http.StatusNotFound)
+}
+
+func TestServer_putMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance string
+		body     interface{}
+		want     int
+	}{
+		{
+			name: "valid", instance: "test", want: http.StatusNoContent,
+			body: controller.Metadata{Owner: "alice", Description: "BGP full table", Labels: map[string]string{"team": "bng"}},
+		},
+		{name: "not_exists", instance: "missing", body: controller.Metadata{}, want: http.StatusNotFound},
+		{name: "invalid_label", instance: "test", body: controller.Metadata{Labels: map[string]string{"team": "a b"}}, want: http.StatusBadRequest},
+		{name: "bad_body", instance: "test", body: "metadata", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return configFolder
+				},
+				SetMetadataFunc: func(name string, metadata controller.Metadata) error {
+					if name != "test" {
+						return controller.ErrBlasterNotExists
+					}
+					return nil
+				},
+			}
+			server := httptest.NewServer(NewServer(repository))
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			e.PUT("/api/v1/instances/{instance_name}/metadata", tt.instance).
+				WithJSON(tt.body).
+				Expect().
+				Status(tt.want)
+			if tt.want == http.StatusNoContent {
+				require.Len(t, repository.SetMetadataCalls(), 1)
+				require.Equal(t, tt.body, repository.SetMetadataCalls()[0].Metadata)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	interfaceNameParameter = "interface_name"
+	// defaultSysfs is the mount point of sysfs.
+	defaultSysfs = "/sys"
+)
+
+// pciAddress is a PCI address like 0000:01:00.0.
+var pciAddress = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+
+// dpdkDrivers are the kernel drivers used to bind a device to DPDK.
+var dpdkDrivers = map[string]bool{
+	"vfio-pci":        true,
+	"igb_uio":         true,
+	"uio_pci_generic": true,
+	"uio_hv_generic":  true,
+}
+
+// InterfaceInfo holds the information about a network interface.
+type InterfaceInfo struct {
+	Name  string   `json:"name"`
+	MTU   int      `json:"mtu"`
+	Flags []string `json:"flags"`
+	Mac   string   `json:"mac"`
+	// Driver is the kernel driver of the device, e.g. ixgbe or vfio-pci.
+	Driver string `json:"driver,omitempty"`
+	// PCI is the PCI address of the device, e.g. 0000:01:00.0.
+	PCI string `json:"pci,omitempty"`
+	// NUMANode is the NUMA node of the device, if known.
+	NUMANode *int `json:"numa_node,omitempty"`
+	// Speed is the link speed in Mbit/s, if known.
+	Speed *int `json:"speed,omitempty"`
+	// OperState is the operational state, e.g. up, down or unknown.
+	OperState string `json:"operstate,omitempty"`
+	// Carrier is true if the link is up, it is unknown if the interface is down.
+	Carrier *bool    `json:"carrier,omitempty"`
+	IPv4    []string `json:"ipv4,omitempty"`
+	IPv6    []string `json:"ipv6,omitempty"`
+	// DPDK is true if the device is bound to a DPDK driver, those devices
+	// are no network interfaces of the kernel and named by their PCI address.
+	DPDK bool `json:"dpdk"`
+	// Instance is the running instance using the interface.
+	Instance string `json:"instance,omitempty"`
+	// Statistics are only returned for a single interface.
+	Statistics *InterfaceStatistics `json:"statistics,omitempty"`
+}
+
+// InterfaceStatistics are the counters of a network interface.
+type InterfaceStatistics struct {
+	RxPackets uint64 `json:"rx_packets"`
+	RxBytes   uint64 `json:"rx_bytes"`
+	RxErrors  uint64 `json:"rx_errors"`
+	RxDropped uint64 `json:"rx_dropped"`
+	TxPackets uint64 `json:"tx_packets"`
+	TxBytes   uint64 `json:"tx_bytes"`
+	TxErrors  uint64 `json:"tx_errors"`
+	TxDropped uint64 `json:"tx_dropped"`
+}
+
+// getReadableInterfaceFlags converts interface flags to a readable format.
+func getReadableInterfaceFlags(flags net.Flags) []string {
+	var readableFlags []string
+	flagMap := map[net.Flags]string{
+		net.FlagUp:           "up",
+		net.FlagBroadcast:    "broadcast",
+		net.FlagLoopback:     "loopback",
+		net.FlagPointToPoint: "point-to-point",
+		net.FlagMulticast:    "multicast",
+	}
+
+	for flag, desc := range flagMap {
+		if flags&flag != 0 {
+			readableFlags = append(readableFlags, desc)
+		}
+	}
+
+	return readableFlags
+}
+
+// readSysfs returns the trimmed content of a sysfs attribute.
+func readSysfs(file string) (string, bool) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(b)), true
+}
+
+// readSysfsInt returns a sysfs attribute as integer.
+func readSysfsInt(file string) (int, bool) {
+	value, ok := readSysfs(file)
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(value)
+	return i, err == nil
+}
+
+// deviceInfo adds the driver, PCI address and NUMA node of a device folder.
+func deviceInfo(device string, info *InterfaceInfo) {
+	if driver, err := filepath.EvalSymlinks(filepath.Join(device, "driver")); err == nil {
+		info.Driver = filepath.Base(driver)
+		info.DPDK = dpdkDrivers[info.Driver]
+	}
+	// The device of virtio interfaces is a child of the PCI device.
+	if target, err := filepath.EvalSymlinks(device); err == nil {
+		for _, folder := range []string{target, filepath.Dir(target)} {
+			if pciAddress.MatchString(filepath.Base(folder)) {
+				info.PCI = filepath.Base(folder)
+				break
+			}
+		}
+	}
+	if node, ok := readSysfsInt(filepath.Join(device, "numa_node")); ok && node >= 0 {
+		info.NUMANode = &node
+	}
+}
+
+// sysfsInfo adds the information of /sys/class/net/<name> to the interface.
+func sysfsInfo(sysfs string, info *InterfaceInfo) {
+	folder := filepath.Join(sysfs, "class", "net", info.Name)
+	deviceInfo(filepath.Join(folder, "device"), info)
+	if state, ok := readSysfs(filepath.Join(folder, "operstate")); ok {
+		info.OperState = state
+	}
+	// speed and carrier are not readable if the interface is down.
+	if speed, ok := readSysfsInt(filepath.Join(folder, "speed")); ok && speed >= 0 {
+		info.Speed = &speed
+	}
+	if carrier, ok := readSysfsInt(filepath.Join(folder, "carrier")); ok {
+		up := carrier == 1
+		info.Carrier = &up
+	}
+}
+
+// sysfsStatistics returns the counters of /sys/class/net/<name>/statistics.
+func sysfsStatistics(sysfs string, name string) *InterfaceStatistics {
+	folder := filepath.Join(sysfs, "class", "net", name, "statistics")
+	read := func(counter string) uint64 {
+		value, _ := readSysfs(filepath.Join(folder, counter))
+		i, _ := strconv.ParseUint(value, 10, 64)
+		return i
+	}
+	return &InterfaceStatistics{
+		RxPackets: read("rx_packets"),
+		RxBytes:   read("rx_bytes"),
+		RxErrors:  read("rx_errors"),
+		RxDropped: read("rx_dropped"),
+		TxPackets: read("tx_packets"),
+		TxBytes:   read("tx_bytes"),
+		TxErrors:  read("tx_errors"),
+		TxDropped: read("tx_dropped"),
+	}
+}
+
+// dpdkDevices returns the PCI devices bound to a DPDK driver.
+func dpdkDevices(sysfs string) []InterfaceInfo {
+	var devices []InterfaceInfo
+	folders, _ := filepath.Glob(filepath.Join(sysfs, "bus", "pci", "devices", "*"))
+	for _, folder := range folders {
+		info := InterfaceInfo{Name: filepath.Base(folder)}
+		deviceInfo(folder, &info)
+		if info.DPDK {
+			info.PCI = info.Name
+			devices = append(devices, info)
+		}
+	}
+	return devices
+}
+
+// interfaceInfo returns the information of a kernel network interface.
+func interfaceInfo(sysfs string, iface net.Interface) InterfaceInfo {
+	info := InterfaceInfo{
+		Name:  iface.Name,
+		MTU:   iface.MTU,
+		Flags: getReadableInterfaceFlags(iface.Flags),
+		Mac:   iface.HardwareAddr.String(),
+	}
+	if addrs, err := iface.Addrs(); err == nil {
+		for _, addr := range addrs {
+			if ip, ok := addr.(*net.IPNet); ok {
+				if ip.IP.To4() != nil {
+					info.IPv4 = append(info.IPv4, ip.String())
+				} else {
+					info.IPv6 = append(info.IPv6, ip.String())
+				}
+			}
+		}
+	}
+	sysfsInfo(sysfs, &info)
+	return info
+}
+
+// getInterfaces returns a list of all network interfaces and DPDK devices.
+func getInterfaces(sysfs string) []InterfaceInfo {
+	var interfacesInfo []InterfaceInfo
+
+	// Get the list of network interfaces.
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return interfacesInfo // Return the empty slice if there's an error.
+	}
+
+	// Populate InterfaceInfo for each interface.
+	for _, iface := range interfaces {
+		interfacesInfo = append(interfacesInfo, interfaceInfo(sysfs, iface))
+	}
+	return append(interfacesInfo, dpdkDevices(sysfs)...)
+}
+
+// claimedInterfaces returns the running instance of every interface used
+// by the configuration of a running instance.
+func (s *Server) claimedInterfaces() map[string]string {
+	claimed := map[string]string{}
+	instances := s.repository.Instances()
+	sort.Strings(instances)
+	for _, instance := range instances {
+		if !s.repository.Running(instance) {
+			continue
+		}
+		for _, name := range s.configInterfaces(instance) {
+			if _, ok := claimed[name]; !ok {
+				claimed[name] = instance
+			}
+		}
+	}
+	return claimed
+}
+
+func (s *Server) interfaces() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		interfaces := getInterfaces(s.sysfs)
+		claimed := s.claimedInterfaces()
+		for i := range interfaces {
+			interfaces[i].Instance = claimed[interfaces[i].Name]
+		}
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(interfaces)
+	}
+}
+
+// networkInterface returns the information and the counters of a network
+// interface or DPDK device.
+func (s *Server) networkInterface() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)[interfaceNameParameter]
+		var info *InterfaceInfo
+		if iface, err := net.InterfaceByName(name); err == nil {
+			i := interfaceInfo(s.sysfs, *iface)
+			i.Statistics = sysfsStatistics(s.sysfs, name)
+			info = &i
+		} else {
+			for _, device := range dpdkDevices(s.sysfs) {
+				if device.Name == name {
+					info = &device
+					break
+				}
+			}
+		}
+		if info == nil {
+			JSONNotFound(w, r)
+			return
+		}
+		info.Instance = s.claimedInterfaces()[name]
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(info)
+	}
+}
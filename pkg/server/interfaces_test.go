@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+// fakeSysfs returns a sysfs with the loopback interface on the PCI device
+// 0000:01:00.0 and the PCI device 0000:02:00.0 bound to vfio-pci.
+func fakeSysfs(t *testing.T) string {
+	t.Helper()
+	sysfs := t.TempDir()
+	write := func(file string, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(sysfs, file)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(sysfs, file), []byte(content+"\n"), 0o644))
+	}
+	link := func(target string, name string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(sysfs, name)), 0o755))
+		require.NoError(t, os.Symlink(filepath.Join(sysfs, target), filepath.Join(sysfs, name)))
+	}
+	for _, driver := range []string{"ixgbe", "vfio-pci"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(sysfs, "bus/pci/drivers", driver), 0o755))
+	}
+	write("devices/pci0000:00/0000:01:00.0/numa_node", "0")
+	link("bus/pci/drivers/ixgbe", "devices/pci0000:00/0000:01:00.0/driver")
+	link("devices/pci0000:00/0000:01:00.0", "bus/pci/devices/0000:01:00.0")
+	write("devices/pci0000:00/0000:02:00.0/numa_node", "1")
+	link("bus/pci/drivers/vfio-pci", "devices/pci0000:00/0000:02:00.0/driver")
+	link("devices/pci0000:00/0000:02:00.0", "bus/pci/devices/0000:02:00.0")
+
+	link("devices/pci0000:00/0000:01:00.0", "class/net/lo/device")
+	write("class/net/lo/operstate", "up")
+	write("class/net/lo/carrier", "1")
+	write("class/net/lo/speed", "10000")
+	write("class/net/lo/statistics/rx_packets", "10")
+	write("class/net/lo/statistics/tx_bytes", "1500")
+	return sysfs
+}
+
+func TestServer_interfaces(t *testing.T) {
+	folder := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "test"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "test", controller.ConfigFilename),
+		[]byte(`{"interfaces": {"network": {"interface": "lo"}}}`), 0o644))
+	repository := &controller.RepositoryMock{
+		ConfigFolderFunc: func() string {
+			return folder
+		},
+		InstancesFunc: func() []string {
+			return []string{"test", "stopped"}
+		},
+		RunningFunc: func(name string) bool {
+			return name == "test"
+		},
+	}
+	s := NewServer(repository)
+	s.sysfs = fakeSysfs(t)
+	server := httptest.NewServer(s)
+	defer server.Close()
+	e := httpexpect.New(t, server.URL)
+
+	list := e.GET("/api/v1/interfaces").Expect().Status(http.StatusOK).JSON().Array()
+	var names []string
+	for _, v := range list.Iter() {
+		names = append(names, v.Object().Value("name").String().Raw())
+	}
+	require.Contains(t, names, "lo")
+	require.Contains(t, names, "0000:02:00.0")
+	require.NotContains(t, names, "0000:01:00.0")
+
+	lo := e.GET("/api/v1/interfaces/{interface_name}", "lo").Expect().Status(http.StatusOK).JSON().Object()
+	lo.ValueEqual("driver", "ixgbe").
+		ValueEqual("pci", "0000:01:00.0").
+		ValueEqual("numa_node", 0).
+		ValueEqual("speed", 10000).
+		ValueEqual("operstate", "up").
+		ValueEqual("carrier", true).
+		ValueEqual("dpdk", false).
+		ValueEqual("instance", "test")
+	lo.Value("ipv4").Array().Contains("127.0.0.1/8")
+	lo.Value("statistics").Object().ValueEqual("rx_packets", 10).ValueEqual("tx_bytes", 1500).ValueEqual("rx_errors", 0)
+
+	dpdk := e.GET("/api/v1/interfaces/{interface_name}", "0000:02:00.0").Expect().Status(http.StatusOK).JSON().Object()
+	dpdk.ValueEqual("driver", "vfio-pci").
+		ValueEqual("pci", "0000:02:00.0").
+		ValueEqual("numa_node", 1).
+		ValueEqual("dpdk", true).
+		NotContainsKey("instance")
+
+	e.GET("/api/v1/interfaces/{interface_name}", "missing0").Expect().Status(http.StatusNotFound)
+}
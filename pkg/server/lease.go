@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gorilla/mux"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+const (
+	leaseIDParameter = "lease_id"
+	// leaseIDHeader identifies the lease of the client.
+	leaseIDHeader = "Lease-ID"
+)
+
+// configInterfaces returns the host interfaces of the configuration of an instance.
+func (s *Server) configInterfaces(instance string) []string {
+	config, err := os.ReadFile(filepath.Join(s.repository.ConfigFolder(), instance, controller.ConfigFilename))
+	if err != nil {
+		return nil
+	}
+	interfaces, _ := controller.ConfigInterfaces(config)
+	return interfaces
+}
+
+// checkLease writes 423 and returns false if the instance or one of the interfaces
+// is leased by someone else than the holder of the lease of the request.
+func (s *Server) checkLease(w http.ResponseWriter, r *http.Request, instance string, interfaces []string) bool {
+	if err := s.leases.Check(r.Header.Get(leaseIDHeader), instance, interfaces); err != nil {
+		JSONError(w, err.Error(), http.StatusLocked)
+		return false
+	}
+	return true
+}
+
+// leaseList lists the active leases without their IDs.
+func (s *Server) leaseList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(s.leases.List())
+	}
+}
+
+// acquireLease grants a lease on an instance and/or host interfaces.
+func (s *Server) acquireLease() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request controller.Lease
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if request.Instance != "" {
+			request.Instance = cleanPathVariable(request.Instance)
+		}
+		lease, err := s.leases.Acquire(request)
+		if errors.Is(err, controller.ErrInvalidLease) {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, controller.ErrLeased) {
+			JSONError(w, err.Error(), http.StatusLocked)
+			return
+		}
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(lease)
+	}
+}
+
+// renewLease is the heartbeat of a lease, which extends the lease by its TTL.
+func (s *Server) renewLease() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lease, err := s.leases.Renew(mux.Vars(r)[leaseIDParameter])
+		if err == controller.ErrLeaseNotExists {
+			JSONNotFound(w, r)
+			return
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(lease)
+	}
+}
+
+// releaseLease releases a lease before it expires.
+func (s *Server) releaseLease() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.leases.Release(mux.Vars(r)[leaseIDParameter]); err == controller.ErrLeaseNotExists {
+			JSONNotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+func TestServer_leases(t *testing.T) {
+	folder := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "other"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "other", controller.ConfigFilename),
+		[]byte(`{"interfaces": {"access": [{"interface": "eth1"}]}}`), 0o644))
+	repository := &controller.RepositoryMock{
+		ConfigFolderFunc: func() string {
+			return folder
+		},
+		ExistsFunc: func(name string) bool {
+			return true
+		},
+		RunningFunc: func(name string) bool {
+			return false
+		},
+		CreateFunc: func(name string, config []byte) error {
+			return nil
+		},
+		DeleteFunc: func(name string) error {
+			return nil
+		},
+		StartFunc: func(name string, runningConfig controller.RunningConfig) error {
+			return nil
+		},
+		StopFunc: func(name string) {},
+		KillFunc: func(name string) {},
+		AllowUploadFunc: func() bool {
+			return true
+		},
+		LibraryFolderFunc: func() string {
+			return filepath.Join(folder, ".library")
+		},
+	}
+	server := httptest.NewServer(NewServer(repository))
+	defer server.Close()
+	e := httpexpect.New(t, server.URL)
+
+	e.POST("/api/v1/leases").WithJSON(map[string]interface{}{"holder": "ci-1"}).Expect().Status(http.StatusBadRequest)
+	e.POST("/api/v1/leases").WithJSON(map[string]interface{}{"instance": "test", "ttl": 100000}).Expect().Status(http.StatusBadRequest)
+
+	lease := e.POST("/api/v1/leases").
+		WithJSON(map[string]interface{}{"holder": "ci-1", "instance": "test", "interfaces": []string{"eth1"}, "ttl": 600}).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object()
+	lease.ValueEqual("holder", "ci-1").ValueEqual("ttl", 600)
+	id := lease.Value("id").String().Raw()
+
+	e.POST("/api/v1/leases").
+		WithJSON(map[string]interface{}{"holder": "ci-2", "instance": "test"}).
+		Expect().
+		Status(http.StatusLocked).
+		JSON().Object().Value("message").String().Contains(`leased by "ci-1"`)
+
+	list := e.GET("/api/v1/leases").Expect().Status(http.StatusOK).JSON().Array()
+	list.Length().Equal(1)
+	list.Element(0).Object().NotContainsKey("id").ValueEqual("instance", "test")
+
+	// Others are locked out of the leased instance.
+	e.PUT("/api/v1/instances/{instance_name}", "test").WithBytes([]byte("{}")).Expect().Status(http.StatusLocked)
+	e.POST("/api/v1/instances/{instance_name}/_start", "test").WithJSON(controller.RunningConfig{}).Expect().Status(http.StatusLocked)
+	e.POST("/api/v1/instances/{instance_name}/_stop", "test").Expect().Status(http.StatusLocked)
+	e.POST("/api/v1/instances/{instance_name}/_kill", "test").Expect().Status(http.StatusLocked)
+	e.DELETE("/api/v1/instances/{instance_name}", "test").WithHeader(leaseIDHeader, "wrong").Expect().Status(http.StatusLocked)
+	e.POST("/api/v1/instances/{instance_name}/_command", "test").WithJSON(controller.SocketCommand{Command: "terminate"}).Expect().Status(http.StatusLocked)
+	e.POST("/api/v1/instances/{instance_name}/_upload", "test").WithMultipart().WithFile("file", "streams.json", strings.NewReader("{}")).Expect().Status(http.StatusLocked)
+	e.PUT("/api/v1/instances/{instance_name}/metadata", "test").WithJSON(controller.Metadata{Owner: "ci-2"}).Expect().Status(http.StatusLocked)
+	e.PUT("/api/v1/instances/{instance_name}/files/{file_name}", "test", "streams.json").WithBytes([]byte("{}")).Expect().Status(http.StatusLocked)
+	e.DELETE("/api/v1/instances/{instance_name}/files/{file_name}", "test", "streams.json").Expect().Status(http.StatusLocked)
+	// Downloads are not locked.
+	e.GET("/api/v1/instances/{instance_name}/files/{file_name}", "test", "streams.json").Expect().Status(http.StatusNotFound)
+	e.POST("/api/v1/instances/{instance_name}/streams/_stop", "test").Expect().Status(http.StatusLocked)
+	e.POST("/api/v1/instances/{instance_name}/sessions/{session_id}/_terminate", "test", 1).Expect().Status(http.StatusLocked)
+	e.POST("/api/v1/instances/{instance_name}/protocols/{protocol}/{action}", "test", "bgp", "_disconnect").Expect().Status(http.StatusLocked)
+	// The shared library is not leased.
+	e.PUT("/api/v1/library/{file_name}", "shared.json").WithBytes([]byte("{}")).Expect().Status(http.StatusCreated)
+	// The configuration of other uses the leased interface eth1.
+	e.POST("/api/v1/instances/{instance_name}/_start", "other").WithJSON(controller.RunningConfig{}).Expect().Status(http.StatusLocked)
+	e.POST("/api/v1/instances/{instance_name}/_stop", "other").Expect().Status(http.StatusAccepted)
+	require.Empty(t, repository.CreateCalls())
+	require.Empty(t, repository.StartCalls())
+	require.Empty(t, repository.DeleteCalls())
+
+	// The holder of the lease is not.
+	e.PUT("/api/v1/instances/{instance_name}", "test").WithHeader(leaseIDHeader, id).WithBytes([]byte("{}")).Expect().Status(http.StatusNoContent)
+	e.POST("/api/v1/instances/{instance_name}/_start", "test").WithHeader(leaseIDHeader, id).WithJSON(controller.RunningConfig{}).Expect().Status(http.StatusNoContent)
+	e.POST("/api/v1/instances/{instance_name}/_start", "other").WithHeader(leaseIDHeader, id).WithJSON(controller.RunningConfig{}).Expect().Status(http.StatusNoContent)
+	e.POST("/api/v1/instances/{instance_name}/_kill", "test").WithHeader(leaseIDHeader, id).Expect().Status(http.StatusAccepted)
+	e.DELETE("/api/v1/instances/{instance_name}", "test").WithHeader(leaseIDHeader, id).Expect().Status(http.StatusNoContent)
+
+	e.POST("/api/v1/leases/{lease_id}/_renew", id).Expect().Status(http.StatusOK).JSON().Object().ValueEqual("id", id)
+	e.DELETE("/api/v1/leases/{lease_id}", id).Expect().Status(http.StatusNoContent)
+	e.DELETE("/api/v1/leases/{lease_id}", id).Expect().Status(http.StatusNotFound)
+	e.POST("/api/v1/leases/{lease_id}/_renew", id).Expect().Status(http.StatusNotFound)
+
+	e.DELETE("/api/v1/instances/{instance_name}", "test").Expect().Status(http.StatusNoContent)
+}
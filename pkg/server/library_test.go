@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+// libraryRepository returns a repository mock with the library folder.
+func libraryRepository(library string, upload bool) *controller.RepositoryMock {
+	return &controller.RepositoryMock{
+		ConfigFolderFunc: func() string {
+			return filepath.Dir(library)
+		},
+		LibraryFolderFunc: func() string {
+			return library
+		},
+		AllowUploadFunc: func() bool {
+			return upload
+		},
+	}
+}
+
+func TestServer_library(t *testing.T) {
+	library := filepath.Join(t.TempDir(), ".library")
+	server := httptest.NewServer(NewServer(libraryRepository(library, true)))
+	defer server.Close()
+	e := httpexpect.New(t, server.URL)
+
+	// The library folder does not exist before the first upload.
+	e.GET("/api/v1/library").Expect().Status(http.StatusOK).JSON().Array().Empty()
+	e.GET("/api/v1/library/{file_name}", "full-table.mrt").Expect().Status(http.StatusNotFound)
+
+	content := "mrt content"
+	e.PUT("/api/v1/library/{file_name}", "full-table.mrt").
+		WithHeader(contentSHA256, sha256Hex(content)).
+		WithText(content).
+		Expect().
+		Status(http.StatusCreated).
+		JSON().Object().ValueEqual("sha256", sha256Hex(content))
+	require.Equal(t, content, string(mustReadFile(t, filepath.Join(library, "full-table.mrt"))))
+
+	e.PUT("/api/v1/library/{file_name}", "streams.json").WithText("{}").Expect().Status(http.StatusCreated)
+	e.PUT("/api/v1/library/{file_name}", "-rf").WithText("{}").Expect().Status(http.StatusBadRequest)
+
+	list := e.GET("/api/v1/library").Expect().Status(http.StatusOK).JSON().Array()
+	list.Length().Equal(2)
+	list.Element(0).Object().ValueEqual("name", "full-table.mrt").ValueEqual("size", len(content))
+	list.Element(1).Object().ValueEqual("name", "streams.json")
+
+	e.GET("/api/v1/library/{file_name}", "full-table.mrt").Expect().Status(http.StatusOK).Body().Equal(content)
+
+	e.DELETE("/api/v1/library/{file_name}", "full-table.mrt").Expect().Status(http.StatusNoContent)
+	e.DELETE("/api/v1/library/{file_name}", "full-table.mrt").Expect().Status(http.StatusNotFound)
+	_, err := os.Stat(filepath.Join(library, "full-table.mrt"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestServer_library_uploadDisabled(t *testing.T) {
+	library := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(library, "full-table.mrt"), []byte("mrt"), 0o644))
+	server := httptest.NewServer(NewServer(libraryRepository(library, false)))
+	defer server.Close()
+	e := httpexpect.New(t, server.URL)
+
+	e.GET("/api/v1/library/{file_name}", "full-table.mrt").Expect().Status(http.StatusOK)
+	e.PUT("/api/v1/library/{file_name}", "full-table.mrt").WithText("new").Expect().Status(http.StatusForbidden)
+	e.DELETE("/api/v1/library/{file_name}", "full-table.mrt").Expect().Status(http.StatusForbidden)
+	require.Equal(t, "mrt", string(mustReadFile(t, filepath.Join(library, "full-table.mrt"))))
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"time"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+// Option helps to configure the Server with options.
+type Option func(server *Server)
+
+// WithMetricsInterval is the option to collect the instance metrics in the
+// background with the given interval instead of collecting them for every scrape.
+func WithMetricsInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.metricsInterval = interval
+	}
+}
+
+// WithMetrics is the option to share the controller self metrics with the repository.
+// Without this option the server creates its own metrics.
+func WithMetrics(metrics *controller.Metrics) Option {
+	return func(s *Server) {
+		s.metrics = metrics
+	}
+}
+
+// WithFileQuota is the option to limit the uploaded files per instance to
+// the given number of bytes and files, zero means unlimited.
+func WithFileQuota(bytes int64, files int) Option {
+	return func(s *Server) {
+		s.quota = quota{bytes: bytes, files: files}
+	}
+}
+
+// WithMaxUploadSize is the option to limit the size of an uploaded file,
+// the default is DefaultMaxUploadSize and zero means unlimited.
+func WithMaxUploadSize(bytes int64) Option {
+	return func(s *Server) {
+		s.maxUploadSize = bytes
+	}
+}
+
+// WithConfigurableInterfaces is the option to allow the configuration of the
+// interfaces matching the patterns (e.g. eth1 or ens1f*), by default no interface
+// could be configured.
+func WithConfigurableInterfaces(patterns ...string) Option {
+	return func(s *Server) {
+		s.configurableInterfaces = patterns
+	}
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+	"github.com/rtbrick/bngblaster-controller/pkg/pcap"
+)
+
+// pcapParameters are the query parameters that select a subset of the capture.
+var pcapParameters = []string{"filter", "start", "end", "max_packets"}
+
+// pcapFilter parses the filter query parameters.
+func pcapFilter(r *http.Request) (pcap.Filter, error) {
+	query := r.URL.Query()
+	f, err := pcap.ParseFilter(query.Get("filter"))
+	if err != nil {
+		return f, err
+	}
+	if f.Start, err = pcap.ParseTime(query.Get("start")); err != nil {
+		return f, err
+	}
+	if f.End, err = pcap.ParseTime(query.Get("end")); err != nil {
+		return f, err
+	}
+	if v := query.Get("max_packets"); v != "" {
+		f.MaxPackets, err = strconv.Atoi(v)
+		if err != nil || f.MaxPackets <= 0 {
+			return f, fmt.Errorf("invalid max_packets %q", v)
+		}
+	}
+	return f, nil
+}
+
+// pcapFile returns the capture of an instance, filtered if any of the
+// filter query parameters is given and the whole file otherwise.
+func (s *Server) pcapFile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		file := filepath.Join(s.repository.ConfigFolder(), instance, controller.RunPcapFilename)
+
+		filtered := false
+		for _, parameter := range pcapParameters {
+			if r.URL.Query().Has(parameter) {
+				filtered = true
+			}
+		}
+		if !filtered {
+			serveFile(w, r, file)
+			return
+		}
+
+		f, err := pcapFilter(r)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		in, err := os.Open(file)
+		if err != nil {
+			JSONNotFound(w, r)
+			return
+		}
+		defer in.Close()
+		reader, err := pcap.NewReader(in)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		if reader.Format() == pcap.FormatPcapng {
+			w.Header().Set(contentType, "application/x-pcapng")
+		} else {
+			w.Header().Set(contentType, "application/vnd.tcpdump.pcap")
+		}
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+instance+"-"+controller.RunPcapFilename+"\"")
+		w.WriteHeader(http.StatusOK)
+		out := bufio.NewWriterSize(w, 1<<16)
+		if _, err := pcap.Slice(reader, out, f); err != nil {
+			log.Warn().Msgf("failed to filter %s of %s: %s", controller.RunPcapFilename, instance, err.Error())
+		}
+		_ = out.Flush()
+	}
+}
+
+// pcapSummary returns the statistics of the capture of an instance.
+func (s *Server) pcapSummary() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		n := 10
+		if v := r.URL.Query().Get("top"); v != "" {
+			var err error
+			n, err = strconv.Atoi(v)
+			if err != nil || n < 0 {
+				JSONError(w, fmt.Sprintf("invalid top %q", v), http.StatusBadRequest)
+				return
+			}
+		}
+		in, err := os.Open(filepath.Join(s.repository.ConfigFolder(), instance, controller.RunPcapFilename))
+		if err != nil {
+			JSONNotFound(w, r)
+			return
+		}
+		defer in.Close()
+		reader, err := pcap.NewReader(in)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		summary, err := pcap.Summarize(reader, n)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(summary)
+	}
+}
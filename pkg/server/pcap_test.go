@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+// testCapture returns a classic pcap file with an ARP packet on VLAN 100
+// followed by an untagged IPv4 packet one second later.
+func testCapture() []byte {
+	mac := []byte{0x02, 0, 0, 0, 0, 1, 0x02, 0, 0, 0, 0, 2}
+	arp := append(append([]byte{}, mac...), 0x81, 0x00, 0x00, 100, 0x08, 0x06)
+	arp = append(arp, make([]byte, 28)...)
+	ip := append(append([]byte{}, mac...), 0x08, 0x00, 0x45)
+	ip = append(ip, make([]byte, 19)...)
+
+	capture := binary.LittleEndian.AppendUint32(nil, 0xa1b2c3d4)
+	capture = binary.LittleEndian.AppendUint16(capture, 2)
+	capture = binary.LittleEndian.AppendUint16(capture, 4)
+	capture = append(capture, make([]byte, 8)...)
+	capture = binary.LittleEndian.AppendUint32(capture, 65535)
+	capture = binary.LittleEndian.AppendUint32(capture, 1)
+	for i, packet := range [][]byte{arp, ip} {
+		capture = binary.LittleEndian.AppendUint32(capture, uint32(1735725600+i))
+		capture = binary.LittleEndian.AppendUint32(capture, 0)
+		capture = binary.LittleEndian.AppendUint32(capture, uint32(len(packet)))
+		capture = binary.LittleEndian.AppendUint32(capture, uint32(len(packet)))
+		capture = append(capture, packet...)
+	}
+	return capture
+}
+
+func TestServer_pcapFile(t *testing.T) {
+	folder := t.TempDir()
+	capture := testCapture()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "test"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "invalid"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "test", controller.RunPcapFilename), capture, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "invalid", controller.RunPcapFilename), []byte("invalid"), 0o644))
+
+	// The first record starts after the 24 bytes file header and ends
+	// after the 16 bytes record header and the 46 bytes packet.
+	header, first := capture[:24], capture[24:86]
+
+	tests := []struct {
+		name     string
+		instance string
+		query    string
+		want     int
+		wantBody []byte
+	}{
+		{name: "unfiltered", instance: "test", want: http.StatusOK, wantBody: capture},
+		{name: "protocol", instance: "test", query: "filter=arp", want: http.StatusOK, wantBody: append(append([]byte{}, header...), first...)},
+		{name: "vlan", instance: "test", query: "filter=vlan:100", want: http.StatusOK, wantBody: append(append([]byte{}, header...), first...)},
+		{name: "window", instance: "test", query: "start=0.5", want: http.StatusOK, wantBody: append(append([]byte{}, header...), capture[86:]...)},
+		{name: "max_packets", instance: "test", query: "max_packets=1", want: http.StatusOK, wantBody: append(append([]byte{}, header...), first...)},
+		{name: "no_match", instance: "test", query: "filter=dhcp", want: http.StatusOK, wantBody: header},
+		{name: "invalid_filter", instance: "test", query: "filter=unknown", want: http.StatusBadRequest},
+		{name: "invalid_start", instance: "test", query: "start=yesterday", want: http.StatusBadRequest},
+		{name: "invalid_max_packets", instance: "test", query: "max_packets=0", want: http.StatusBadRequest},
+		{name: "invalid_file", instance: "invalid", query: "filter=arp", want: http.StatusUnprocessableEntity},
+		{name: "not_exists", instance: "missing", query: "filter=arp", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return folder
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/instances/{instance_name}/run.pcap", tt.instance).
+				WithQueryString(tt.query).
+				Expect().
+				Status(tt.want)
+			if tt.want == http.StatusOK {
+				require.Equal(t, string(tt.wantBody), response.Body().Raw())
+			}
+		})
+	}
+}
+
+func TestServer_pcapSummary(t *testing.T) {
+	folder := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "test"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "invalid"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "test", controller.RunPcapFilename), testCapture(), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "invalid", controller.RunPcapFilename), []byte("invalid"), 0o644))
+
+	tests := []struct {
+		name     string
+		instance string
+		query    string
+		want     int
+		wantTop  int
+	}{
+		{name: "summary", instance: "test", want: http.StatusOK, wantTop: 1},
+		{name: "top", instance: "test", query: "top=0", want: http.StatusOK, wantTop: 0},
+		{name: "invalid_top", instance: "test", query: "top=-1", want: http.StatusBadRequest},
+		{name: "invalid_file", instance: "invalid", want: http.StatusUnprocessableEntity},
+		{name: "not_exists", instance: "missing", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return folder
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/instances/{instance_name}/run.pcap/_summary", tt.instance).
+				WithQueryString(tt.query).
+				Expect().
+				Status(tt.want)
+			if tt.want != http.StatusOK {
+				return
+			}
+			summary := response.JSON().Object()
+			summary.Value("packets").Equal(2)
+			summary.Value("first").Equal("2025-01-01T10:00:00Z")
+			summary.Value("last").Equal("2025-01-01T10:00:01Z")
+			summary.Value("ether_types").Object().Equal(map[string]int{"0x0806": 1, "0x0800": 1})
+			summary.Value("vlans").Object().Equal(map[string]int{"100": 1})
+			summary.Value("protocols").Object().Equal(map[string]int{"arp": 1, "ipv4": 1})
+			summary.Value("top_talkers").Object().Value("mac").Array().Length().Equal(tt.wantTop)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+const (
+	protocolParameter = "protocol"
+	resourceParameter = "resource"
+	actionParameter   = "action"
+
+	// fileArgument is the command argument that references an uploaded file.
+	fileArgument = "file"
+)
+
+// protocolCommand describes how a protocol resource or action maps to a socket command.
+type protocolCommand struct {
+	// command is the socket command of the bngblaster.
+	command string
+	// arguments maps the query parameters to the command arguments.
+	arguments map[string]string
+}
+
+// protocolResources are the readable resources per routing protocol.
+var protocolResources = map[string]map[string]protocolCommand{
+	"isis": {
+		"adjacencies": {command: "isis-adjacencies"},
+		"database": {
+			command:   "isis-database",
+			arguments: map[string]string{"instance": "instance", "level": "level"},
+		},
+	},
+	"ospf": {
+		"interfaces": {
+			command:   "ospf-interfaces",
+			arguments: map[string]string{"instance": "instance"},
+		},
+		"neighbors": {
+			command:   "ospf-neighbors",
+			arguments: map[string]string{"instance": "instance"},
+		},
+		"database": {
+			command:   "ospf-database",
+			arguments: map[string]string{"instance": "instance"},
+		},
+	},
+	"bgp": {
+		"sessions": {
+			command:   "bgp-sessions",
+			arguments: map[string]string{"local_ip": "local-ip", "peer_ip": "peer-ip"},
+		},
+	},
+	"ldp": {
+		"adjacencies": {
+			command:   "ldp-adjacencies",
+			arguments: map[string]string{"instance": "ldp-instance-id"},
+		},
+		"sessions": {
+			command:   "ldp-sessions",
+			arguments: map[string]string{"instance": "ldp-instance-id", "local_ip": "local-ip", "peer_ip": "peer-ip"},
+		},
+		"database": {
+			command:   "ldp-database",
+			arguments: map[string]string{"instance": "ldp-instance-id"},
+		},
+	},
+}
+
+// protocolActions are the actions per routing protocol.
+var protocolActions = map[string]map[string]string{
+	"isis": {
+		"_load_mrt": "isis-load-mrt",
+		"_teardown": "isis-teardown",
+	},
+	"ospf": {
+		"_load_mrt": "ospf-load-mrt",
+		"_teardown": "ospf-teardown",
+	},
+	"bgp": {
+		"_raw_update": "bgp-raw-update",
+		"_disconnect": "bgp-disconnect",
+		"_teardown":   "bgp-teardown",
+	},
+	"ldp": {
+		"_raw_update": "ldp-raw-update",
+		"_disconnect": "ldp-disconnect",
+		"_teardown":   "ldp-teardown",
+	},
+}
+
+// argumentValue converts a query parameter into a command argument value.
+// Numbers are passed as integer, everything else as string.
+func argumentValue(v string) interface{} {
+	if i, err := strconv.Atoi(v); err == nil {
+		return i
+	}
+	return v
+}
+
+func (s *Server) protocolResource() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		protocol := mux.Vars(r)[protocolParameter]
+		pc, ok := protocolResources[protocol][mux.Vars(r)[resourceParameter]]
+		if !ok {
+			JSONNotFound(w, r)
+			return
+		}
+
+		command := controller.SocketCommand{
+			Command:   pc.command,
+			Arguments: map[string]interface{}{},
+		}
+		for parameter, argument := range pc.arguments {
+			if v := r.URL.Query().Get(parameter); v != "" {
+				command.Arguments[argument] = argumentValue(v)
+			}
+		}
+		var cr map[string]json.RawMessage
+		if !s.socketCommand(w, instance, command, &cr) {
+			return
+		}
+		result, ok := cr[pc.command]
+		if !ok {
+			result = json.RawMessage("[]")
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(result)
+	}
+}
+
+func (s *Server) protocolAction() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
+		protocol := mux.Vars(r)[protocolParameter]
+		name, ok := protocolActions[protocol][mux.Vars(r)[actionParameter]]
+		if !ok {
+			JSONNotFound(w, r)
+			return
+		}
+
+		command := controller.SocketCommand{
+			Command:   name,
+			Arguments: map[string]interface{}{},
+		}
+		if err := json.NewDecoder(r.Body).Decode(&command.Arguments); err != nil && err != io.EOF {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if v, ok := command.Arguments[fileArgument]; ok {
+			file, err := s.instanceFile(instance, fmt.Sprint(v))
+			if err != nil {
+				JSONError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			command.Arguments[fileArgument] = file
+		}
+		if !s.socketCommand(w, instance, command, nil) {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// instanceFile returns the absolute path of a file in the instance folder,
+// e.g. a file uploaded with the upload endpoint.
+func (s *Server) instanceFile(instance string, name string) (string, error) {
+	base := filepath.Base(filepath.Clean("/" + name))
+	if base == "/" || base != name {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	file, err := filepath.Abs(filepath.Join(s.repository.ConfigFolder(), instance, base))
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(file); err != nil || info.IsDir() {
+		return "", fmt.Errorf("file %q not found", name)
+	}
+	return file, nil
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+func TestServer_protocolResource(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		query       string
+		result      string
+		wantCommand controller.SocketCommand
+		want        int
+	}{
+		{
+			name:   "isis_adjacencies",
+			path:   "isis/adjacencies",
+			result: `{"status": "ok", "code": 200, "isis-adjacencies": [{"interface": "eth1", "adjacency-state": "Up"}]}`,
+			wantCommand: controller.SocketCommand{
+				Command:   "isis-adjacencies",
+				Arguments: map[string]interface{}{},
+			},
+			want: http.StatusOK,
+		}, {
+			name:   "isis_database",
+			path:   "isis/database",
+			query:  "instance=1&level=2",
+			result: `{"status": "ok", "code": 200, "isis-database": []}`,
+			wantCommand: controller.SocketCommand{
+				Command:   "isis-database",
+				Arguments: map[string]interface{}{"instance": 1, "level": 2},
+			},
+			want: http.StatusOK,
+		}, {
+			name:   "bgp_sessions",
+			path:   "bgp/sessions",
+			query:  "peer_ip=10.0.0.1",
+			result: `{"status": "ok", "code": 200, "bgp-sessions": []}`,
+			wantCommand: controller.SocketCommand{
+				Command:   "bgp-sessions",
+				Arguments: map[string]interface{}{"peer-ip": "10.0.0.1"},
+			},
+			want: http.StatusOK,
+		}, {
+			name: "unknown_resource",
+			path: "ospf/adjacencies",
+			want: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return configFolder
+				},
+				CommandFunc: func(name string, command controller.SocketCommand) ([]byte, error) {
+					return []byte(tt.result), nil
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/instances/{instance_name}/protocols/"+tt.path, tt.name).
+				WithQueryString(tt.query).
+				Expect().
+				Status(tt.want)
+			if tt.want != http.StatusOK {
+				require.Empty(t, repository.CommandCalls())
+				return
+			}
+			response.JSON().Array()
+			require.Len(t, repository.CommandCalls(), 1)
+			require.Equal(t, tt.wantCommand, repository.CommandCalls()[0].Command)
+		})
+	}
+}
+
+func TestServer_protocolAction(t *testing.T) {
+	folder := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "test"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "test", "isis.mrt"), []byte{}, 0o644))
+
+	tests := []struct {
+		name        string
+		path        string
+		body        map[string]interface{}
+		wantCommand controller.SocketCommand
+		want        int
+	}{
+		{
+			name: "isis_load_mrt",
+			path: "isis/_load_mrt",
+			body: map[string]interface{}{"file": "isis.mrt", "instance": 1},
+			wantCommand: controller.SocketCommand{
+				Command:   "isis-load-mrt",
+				Arguments: map[string]interface{}{"file": filepath.Join(folder, "test", "isis.mrt"), "instance": float64(1)},
+			},
+			want: http.StatusNoContent,
+		}, {
+			name: "bgp_teardown",
+			path: "bgp/_teardown",
+			wantCommand: controller.SocketCommand{
+				Command:   "bgp-teardown",
+				Arguments: map[string]interface{}{},
+			},
+			want: http.StatusNoContent,
+		}, {
+			name: "file_not_found",
+			path: "bgp/_raw_update",
+			body: map[string]interface{}{"file": "missing.bgp"},
+			want: http.StatusBadRequest,
+		}, {
+			name: "file_traversal",
+			path: "ldp/_raw_update",
+			body: map[string]interface{}{"file": "../test/isis.mrt"},
+			want: http.StatusBadRequest,
+		}, {
+			name: "unknown_action",
+			path: "ospf/_raw_update",
+			want: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return folder
+				},
+				CommandFunc: func(name string, command controller.SocketCommand) ([]byte, error) {
+					return []byte(`{"status": "ok", "code": 200}`), nil
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			request := e.POST("/api/v1/instances/{instance_name}/protocols/"+tt.path, "test")
+			if tt.body != nil {
+				request.WithJSON(tt.body)
+			}
+			request.Expect().Status(tt.want)
+			if tt.want != http.StatusNoContent {
+				require.Empty(t, repository.CommandCalls())
+				return
+			}
+			require.Len(t, repository.CommandCalls(), 1)
+			require.Equal(t, tt.wantCommand, repository.CommandCalls()[0].Command)
+		})
+	}
+}
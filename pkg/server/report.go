@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/report"
+)
+
+// report renders the HTML report of the last run of an instance.
+func (s *Server) report() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		if !s.repository.Exists(instance) {
+			JSONNotFound(w, r)
+			return
+		}
+
+		var buf bytes.Buffer
+		folder := filepath.Join(s.repository.ConfigFolder(), instance)
+		if err := report.Generate(&buf, folder, instance, s.repository.Running(instance)); err != nil {
+			log.Error().Msgf("failed to generate report of %s: %s", instance, err.Error())
+			JSONError(w, "not able to generate report", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(contentType, "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buf.Bytes())
+	}
+}
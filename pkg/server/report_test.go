@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+func TestServer_report(t *testing.T) {
+	folder := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "test"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "test", controller.RunReportFilename), []byte(`{"report": {"sessions": 1}}`), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "invalid"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "invalid", controller.RunReportFilename), []byte(`{`), 0o644))
+
+	tests := []struct {
+		name     string
+		instance string
+		want     int
+	}{
+		{name: "report", instance: "test", want: http.StatusOK},
+		{name: "invalid_report", instance: "invalid", want: http.StatusInternalServerError},
+		{name: "not_exists", instance: "missing", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return folder
+				},
+				ExistsFunc: func(name string) bool {
+					_, err := os.Stat(filepath.Join(folder, name))
+					return err == nil
+				},
+				RunningFunc: func(name string) bool {
+					return false
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/instances/{instance_name}/report.html", tt.instance).
+				Expect().
+				Status(tt.want)
+			if tt.want == http.StatusOK {
+				response.ContentType("text/html", "utf-8")
+				response.Body().Contains("BNG Blaster Report - test")
+			}
+		})
+	}
+}
@@ -5,21 +5,23 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
-	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 
 	"github.com/rtbrick/bngblaster-controller/pkg/controller"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -43,14 +45,23 @@ type Server struct {
 	router     *mux.Router
 	prom       *controller.Prom
 	repository controller.Repository
-}
 
-// InterfaceInfo holds the information about a network interface.
-type InterfaceInfo struct {
-	Name  string   `json:"name"`
-	MTU   int      `json:"mtu"`
-	Flags []string `json:"flags"`
-	Mac   string   `json:"mac"`
+	metricsInterval time.Duration
+	metrics         *controller.Metrics
+
+	// quota limits the uploaded files per instance.
+	quota quota
+	// maxUploadSize is the maximum size of an uploaded file.
+	maxUploadSize int64
+	filesMu       sync.Mutex
+	checksums     *checksumCache
+
+	leases *controller.Leases
+	// sysfs is the mount point of sysfs, which is replaced in tests.
+	sysfs string
+	// configurableInterfaces are the patterns of the interfaces, which could be configured.
+	configurableInterfaces []string
+	links                  linkConfigurer
 }
 
 // VersionInfo holds controller version and the parsed output of the `bngblaster -v` command.
@@ -62,17 +73,40 @@ type VersionInfo struct {
 }
 
 // NewServer is a constructor function for Server.
-func NewServer(repository controller.Repository) *Server {
+func NewServer(repository controller.Repository, opts ...Option) *Server {
 	r := &Server{
-		Version:    "dev",
-		router:     mux.NewRouter(),
-		prom:       controller.NewProm(repository),
-		repository: repository,
+		Version:       "dev",
+		router:        mux.NewRouter(),
+		repository:    repository,
+		checksums:     newChecksumCache(),
+		leases:        controller.NewLeases(),
+		sysfs:         defaultSysfs,
+		links:         netlinkConfigurer{},
+		maxUploadSize: DefaultMaxUploadSize,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	if r.metrics == nil {
+		r.metrics = controller.NewMetrics()
+	}
+	r.prom = controller.NewProm(repository, controller.WithPollInterval(r.metricsInterval))
+	r.metrics.Register(r.prom.Registry)
+	r.prom.Start()
 	r.routes()
 	return r
 }
 
+// Gatherer returns the gatherer of all metrics exposed at /metrics.
+func (s *Server) Gatherer() prometheus.Gatherer {
+	return s.prom.Registry
+}
+
+// Close stops the background work of the server, e.g. the metrics poller.
+func (s *Server) Close() {
+	s.prom.Stop()
+}
+
 // ServeHTTP A Handler responds to an HTTP request.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.router.ServeHTTP(w, r)
@@ -87,9 +121,42 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the recorded writer for http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// metricsMiddleware records the request count and latency per route template.
+func (s *Server) metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		s.metrics.ObserveHTTPRequest(route, r.Method, recorder.status, time.Since(start))
+	})
+}
+
 func (s *Server) routes() {
 	const instanceURL = "/api/v1/instances/{instance_name}"
 	s.router.Use(loggingMiddleware)
+	s.router.Use(s.metricsMiddleware)
 	// Expose the registered metrics via HTTP.
 	s.router.Path("/metrics").Methods(http.MethodGet).Handler(promhttp.HandlerFor(
 		s.prom.Registry,
@@ -99,10 +166,15 @@ func (s *Server) routes() {
 	))
 	s.router.Path("/api/v1/version").Methods(http.MethodGet).Handler(s.version())
 	s.router.Path("/api/v1/interfaces").Methods(http.MethodGet).Handler(s.interfaces())
+	s.router.Path("/api/v1/interfaces/{interface_name}").Methods(http.MethodGet).Handler(s.networkInterface())
+	s.router.Path("/api/v1/interfaces/{interface_name}/_configure").Methods(http.MethodPost).Handler(s.configureInterface())
 	s.router.Path("/api/v1/instances").Methods(http.MethodGet).Handler(s.instances())
+	s.router.Path("/api/v1/compare").Methods(http.MethodGet).Handler(s.compare())
+	s.router.Path(instanceURL + "/" + controller.RunPcapFilename).Methods(http.MethodGet).Handler(s.pcapFile())
+	s.router.Path(instanceURL + "/" + controller.RunPcapFilename + "/_summary").Methods(http.MethodGet).Handler(s.pcapSummary())
 	s.router.
 		Path(
-			fmt.Sprintf("%s/{file_name:%s|%s|%s|%s|%s|%s|%s}",
+			fmt.Sprintf("%s/{file_name:%s|%s|%s|%s|%s|%s|%s|%s}",
 				instanceURL,
 				controller.ConfigFilename,
 				controller.RunConfigFilename,
@@ -110,17 +182,61 @@ func (s *Server) routes() {
 				controller.RunReportFilename,
 				controller.RunPcapFilename,
 				controller.RunStdErr,
-				controller.RunStdOut)).
+				controller.RunStdOut,
+				controller.RunTimeseriesFilename)).
 		Methods(http.MethodGet).Handler(s.fileServing(s.repository.ConfigFolder()))
 
 	s.router.Path(instanceURL).Methods(http.MethodGet).Handler(s.status())
 	s.router.Path(instanceURL).Methods(http.MethodPut).Handler(s.create())
 	s.router.Path(instanceURL).Methods(http.MethodDelete).Handler(s.delete())
-	s.router.Path(instanceURL + "/_start").Methods(http.MethodPost).Handler(s.start())
+	s.router.Path(instanceURL + "/_start").Methods(http.MethodPost).Handler(s.start(false))
+	s.router.Path(instanceURL + "/_check").Methods(http.MethodPost).Handler(s.start(true))
 	s.router.Path(instanceURL + "/_stop").Methods(http.MethodPost).Handler(s.stop())
 	s.router.Path(instanceURL + "/_kill").Methods(http.MethodPost).Handler(s.kill())
 	s.router.Path(instanceURL + "/_command").Methods(http.MethodPost).Handler(s.command())
 	s.router.Path(instanceURL + "/_upload").Methods(http.MethodPost).Handler(s.uploadFile())
+	s.router.Path(instanceURL + "/metadata").Methods(http.MethodGet).Handler(s.metadata())
+	s.router.Path(instanceURL + "/metadata").Methods(http.MethodPut).Handler(s.putMetadata())
+	s.router.Path(instanceURL + "/_archive").Methods(http.MethodGet).Handler(s.archive())
+	s.router.Path(instanceURL + "/_import").Methods(http.MethodPost).Handler(s.importArchive())
+
+	const filesURL = instanceURL + "/files"
+	s.router.Path(filesURL).Methods(http.MethodGet).Handler(s.files(s.instanceFolder))
+	s.router.Path(filesURL + "/{file_name}").Methods(http.MethodGet).Handler(s.file(s.instanceFolder))
+	s.router.Path(filesURL + "/{file_name}").Methods(http.MethodPut).Handler(s.putFile(s.instanceFolder, s.quota))
+	s.router.Path(filesURL + "/{file_name}").Methods(http.MethodDelete).Handler(s.deleteFile(s.instanceFolder))
+
+	const leaseURL = "/api/v1/leases"
+	s.router.Path(leaseURL).Methods(http.MethodGet).Handler(s.leaseList())
+	s.router.Path(leaseURL).Methods(http.MethodPost).Handler(s.acquireLease())
+	s.router.Path(leaseURL + "/{lease_id}/_renew").Methods(http.MethodPost).Handler(s.renewLease())
+	s.router.Path(leaseURL + "/{lease_id}").Methods(http.MethodDelete).Handler(s.releaseLease())
+
+	const libraryURL = "/api/v1/library"
+	s.router.Path(libraryURL).Methods(http.MethodGet).Handler(s.files(s.libraryFolder))
+	s.router.Path(libraryURL + "/{file_name}").Methods(http.MethodGet).Handler(s.file(s.libraryFolder))
+	s.router.Path(libraryURL + "/{file_name}").Methods(http.MethodPut).Handler(s.putFile(s.libraryFolder, quota{}))
+	s.router.Path(libraryURL + "/{file_name}").Methods(http.MethodDelete).Handler(s.deleteFile(s.libraryFolder))
+
+	const sessionURL = instanceURL + "/sessions/{session_id}"
+	s.router.Path(instanceURL + "/sessions").Methods(http.MethodGet).Handler(s.sessions())
+	s.router.Path(sessionURL).Methods(http.MethodGet).Handler(s.session())
+	s.router.Path(sessionURL + "/_terminate").Methods(http.MethodPost).Handler(s.sessionAction("session-terminate"))
+	s.router.Path(sessionURL + "/_restart").Methods(http.MethodPost).Handler(s.sessionAction("session-restart"))
+
+	s.router.Path(instanceURL + "/timeseries").Methods(http.MethodGet).Handler(s.timeseries())
+	s.router.Path(instanceURL + "/report.html").Methods(http.MethodGet).Handler(s.report())
+
+	const streamsURL = instanceURL + "/streams"
+	s.router.Path(streamsURL).Methods(http.MethodGet).Handler(s.streams())
+	s.router.Path(streamsURL + "/{flow_id:[0-9]+}").Methods(http.MethodGet).Handler(s.stream())
+	s.router.Path(streamsURL + "/_start").Methods(http.MethodPost).Handler(s.streamAction("stream-start"))
+	s.router.Path(streamsURL + "/_stop").Methods(http.MethodPost).Handler(s.streamAction("stream-stop"))
+	s.router.Path(streamsURL + "/_reset").Methods(http.MethodPost).Handler(s.streamAction("stream-reset"))
+
+	const protocolURL = instanceURL + "/protocols/{protocol:isis|ospf|bgp|ldp}"
+	s.router.Path(protocolURL + "/{action:_[a-z_]+}").Methods(http.MethodPost).Handler(s.protocolAction())
+	s.router.Path(protocolURL + "/{resource:[a-z]+}").Methods(http.MethodGet).Handler(s.protocolResource())
 }
 
 func (s *Server) fileServing(directory string) http.HandlerFunc {
@@ -128,69 +244,7 @@ func (s *Server) fileServing(directory string) http.HandlerFunc {
 		instanceVariable := mux.Vars(r)[instanceNameParameter]
 		instance := cleanPathVariable(instanceVariable)
 		file := mux.Vars(r)["file_name"]
-		http.ServeFile(w, r, path.Join(directory, instance, file))
-	}
-}
-
-func (s *Server) instances() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		instances := s.repository.Instances()
-		w.Header().Set(contentType, applicationJSON)
-		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(instances)
-	}
-}
-
-// getReadableInterfaceFlags converts interface flags to a readable format.
-func getReadableInterfaceFlags(flags net.Flags) []string {
-	var readableFlags []string
-	flagMap := map[net.Flags]string{
-		net.FlagUp:           "up",
-		net.FlagBroadcast:    "broadcast",
-		net.FlagLoopback:     "loopback",
-		net.FlagPointToPoint: "point-to-point",
-		net.FlagMulticast:    "multicast",
-	}
-
-	for flag, desc := range flagMap {
-		if flags&flag != 0 {
-			readableFlags = append(readableFlags, desc)
-		}
-	}
-
-	return readableFlags
-}
-
-// getInterfaces returns a list of all network interfaces.
-func getInterfaces() []InterfaceInfo {
-	var interfacesInfo []InterfaceInfo
-
-	// Get the list of network interfaces.
-	interfaces, err := net.Interfaces()
-	if err != nil {
-		return interfacesInfo // Return the empty slice if there's an error.
-	}
-
-	// Populate InterfaceInfo for each interface.
-	for _, iface := range interfaces {
-		info := InterfaceInfo{
-			Name:  iface.Name,
-			MTU:   iface.MTU,
-			Flags: getReadableInterfaceFlags(iface.Flags),
-			Mac:   iface.HardwareAddr.String(),
-		}
-		interfacesInfo = append(interfacesInfo, info)
-	}
-
-	return interfacesInfo
-}
-
-func (s *Server) interfaces() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		interfaces := getInterfaces()
-		w.Header().Set(contentType, applicationJSON)
-		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(interfaces)
+		serveFile(w, r, path.Join(directory, instance, file))
 	}
 }
 
@@ -239,6 +293,9 @@ func (s *Server) create() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		instanceVariable := mux.Vars(r)[instanceNameParameter]
 		instance := cleanPathVariable(instanceVariable)
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
 		content, err := io.ReadAll(r.Body)
 		if err != nil || len(content) == 0 {
 			http.Error(w, "body not readable", http.StatusBadRequest)
@@ -276,9 +333,9 @@ func (s *Server) status() http.HandlerFunc {
 		w.Header().Set(contentType, applicationJSON)
 		w.WriteHeader(http.StatusOK)
 		if s.repository.Running(instance) {
-			_ = json.NewEncoder(w).Encode(&response{Status: "started"})
+			_ = json.NewEncoder(w).Encode(&response{Status: controller.StatusStarted})
 		} else {
-			_ = json.NewEncoder(w).Encode(&response{Status: "stopped"})
+			_ = json.NewEncoder(w).Encode(&response{Status: controller.StatusStopped})
 		}
 	}
 }
@@ -287,6 +344,9 @@ func (s *Server) delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		instanceVariable := mux.Vars(r)[instanceNameParameter]
 		instance := cleanPathVariable(instanceVariable)
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
 		status := http.StatusNoContent
 		err := s.repository.Delete(instance)
 		if err == controller.ErrBlasterRunning {
@@ -301,7 +361,12 @@ func (s *Server) delete() http.HandlerFunc {
 	}
 }
 
-func (s *Server) start() http.HandlerFunc {
+// start starts an instance or with dryRun checks if it could be started.
+func (s *Server) start(dryRun bool) http.HandlerFunc {
+	start := s.repository.Start
+	if dryRun {
+		start = s.repository.Check
+	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		instanceVariable := mux.Vars(r)[instanceNameParameter]
 		instance := cleanPathVariable(instanceVariable)
@@ -311,10 +376,26 @@ func (s *Server) start() http.HandlerFunc {
 			JSONError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		if err := controller.ValidateMetricLabels(runningConfig.MetricLabels); err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := controller.ValidateStreamMetrics(runningConfig.StreamMetrics); err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := controller.ValidateTimeseries(runningConfig); err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !s.checkLease(w, r, instance, s.configInterfaces(instance)) {
+			return
+		}
 
 		status := http.StatusNoContent
 
-		err = s.repository.Start(instance, runningConfig)
+		err = start(instance, runningConfig)
 		if err == controller.ErrBlasterNotExists {
 			JSONNotFound(w, r)
 			return
@@ -323,6 +404,15 @@ func (s *Server) start() http.HandlerFunc {
 			JSONError(w, errInstanceIsRunning, http.StatusPreconditionFailed)
 			return
 		}
+		if errors.Is(err, controller.ErrLibraryFileNotExists) || errors.Is(err, controller.ErrInvalidStreamConfig) {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var conflict *controller.InterfaceConflictError
+		if errors.As(err, &conflict) {
+			JSONError(w, err.Error(), http.StatusConflict)
+			return
+		}
 		if err != nil {
 			JSONError(w, "not able to start", http.StatusInternalServerError)
 			return
@@ -335,6 +425,9 @@ func (s *Server) stop() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		instanceVariable := mux.Vars(r)[instanceNameParameter]
 		instance := cleanPathVariable(instanceVariable)
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
 		status := http.StatusAccepted
 		s.repository.Stop(instance)
 		w.WriteHeader(status)
@@ -345,6 +438,9 @@ func (s *Server) kill() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		instanceVariable := mux.Vars(r)[instanceNameParameter]
 		instance := cleanPathVariable(instanceVariable)
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
 		status := http.StatusAccepted
 		s.repository.Kill(instance)
 		w.WriteHeader(status)
@@ -358,6 +454,9 @@ func (s *Server) command() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		instanceVariable := mux.Vars(r)[instanceNameParameter]
 		instance := cleanPathVariable(instanceVariable)
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
 		var command controller.SocketCommand
 		err := json.NewDecoder(r.Body).Decode(&command)
 		if err != nil {
@@ -396,10 +495,69 @@ func (s *Server) command() http.HandlerFunc {
 	}
 }
 
+// commandError is returned by invokeCommand if the bngblaster itself
+// responded with an error code.
+type commandError struct {
+	message string
+}
+
+// Error implements error interface.
+func (ce *commandError) Error() string { return ce.message }
+
+// invokeCommand sends a command to the control socket of an instance and decodes
+// the response into v. The response code of the bngblaster is mapped to a well
+// defined HTTP status: 400 and 404 are passed through, all other error codes are
+// reported as 502 bad gateway. The returned error contains the message that
+// should be reported to the client together with the returned status.
+func (s *Server) invokeCommand(instance string, command controller.SocketCommand, v interface{}) (int, error) {
+	result, err := s.repository.Command(instance, command)
+	if err == controller.ErrBlasterNotExists {
+		return http.StatusNotFound, fmt.Errorf("404 page not found")
+	}
+	if err == controller.ErrBlasterNotRunning {
+		return http.StatusPreconditionFailed, fmt.Errorf("instance is not running")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("not able to send command")
+	}
+	var cr controller.CommandResponse
+	if err := json.Unmarshal(result, &cr); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	switch cr.Code {
+	case 0, http.StatusOK:
+	case http.StatusBadRequest, http.StatusNotFound:
+		return cr.Code, &commandError{message: cr.Message}
+	default:
+		return http.StatusBadGateway, &commandError{message: fmt.Sprintf("%s failed with code %d: %s", command.Command, cr.Code, cr.Message)}
+	}
+	if v == nil {
+		return http.StatusOK, nil
+	}
+	if err := json.Unmarshal(result, v); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// socketCommand is like invokeCommand but writes the error response.
+// If false is returned the caller should not write to w anymore.
+func (s *Server) socketCommand(w http.ResponseWriter, instance string, command controller.SocketCommand, v interface{}) bool {
+	status, err := s.invokeCommand(instance, command, v)
+	if err != nil {
+		JSONError(w, err.Error(), status)
+		return false
+	}
+	return true
+}
+
 func (s *Server) uploadFile() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		instanceVariable := mux.Vars(r)[instanceNameParameter]
 		instance := cleanPathVariable(instanceVariable)
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
 		if !s.repository.Exists(instance) {
 			http.Error(w, "instance not found", http.StatusNotFound)
 			return
@@ -410,32 +568,31 @@ func (s *Server) uploadFile() http.HandlerFunc {
 			return
 		}
 
-		err := r.ParseMultipartForm(4000 << 20) // Max upload size set to 4000 MB
+		// The parts are read as stream, only the file is stored.
+		reader, err := r.MultipartReader()
 		if err != nil {
-			http.Error(w, "error parsing multipart form", http.StatusRequestEntityTooLarge)
+			http.Error(w, "error parsing multipart form", http.StatusBadRequest)
 			return
 		}
-
-		file, handler, err := r.FormFile("file")
-		if err != nil {
-			http.Error(w, "error retrieving file", http.StatusBadRequest)
-			return
-		}
-		defer file.Close()
-
-		filePath := filepath.Join(s.repository.ConfigFolder(), instance, handler.Filename)
-
-		destFile, err := os.Create(filePath)
-		if err != nil {
-			http.Error(w, "failed to create file", http.StatusInternalServerError)
-			return
-		}
-		defer destFile.Close()
-
-		_, err = io.Copy(destFile, file)
-		if err != nil {
-			http.Error(w, "failed to save file", http.StatusInternalServerError)
-			return
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				http.Error(w, "error retrieving file", http.StatusBadRequest)
+				return
+			}
+			if part.FormName() != "file" {
+				continue
+			}
+			if err := checkFilename(part.FileName()); err != nil {
+				http.Error(w, err.Error(), filenameStatus(err))
+				return
+			}
+			folder := filepath.Join(s.repository.ConfigFolder(), instance)
+			if _, status, err := s.storeFile(folder, part.FileName(), part, -1, r.Header.Get(contentSHA256), s.quota); err != nil {
+				http.Error(w, err.Error(), status)
+				return
+			}
+			break
 		}
 
 		w.WriteHeader(http.StatusOK)
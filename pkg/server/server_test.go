@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/gavv/httpexpect/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 
 	"github.com/rtbrick/bngblaster-controller/pkg/controller"
@@ -248,6 +249,27 @@ func TestServer_start(t *testing.T) {
 			body:        &controller.RunningConfig{},
 			wantBody:    "not able to start",
 			want:        http.StatusInternalServerError,
+		}, {
+			name:        "library_file_not_exists",
+			resultStart: fmt.Errorf("%w: full-table.mrt", controller.ErrLibraryFileNotExists),
+			body:        &controller.RunningConfig{},
+			want:        http.StatusBadRequest,
+		}, {
+			name:        "interface_conflict",
+			resultStart: &controller.InterfaceConflictError{Instance: "other", Interfaces: []string{"eth1"}},
+			body:        &controller.RunningConfig{},
+			wantBody:    "interface eth1 used by running instance other",
+			want:        http.StatusConflict,
+		}, {
+			name:        "invalid_stream_config",
+			resultStart: fmt.Errorf("%w: streams.json does not exist", controller.ErrInvalidStreamConfig),
+			body:        &controller.RunningConfig{StreamConfig: "streams.json"},
+			want:        http.StatusBadRequest,
+		}, {
+			name:        "reserved_metric_label",
+			resultStart: nil,
+			body:        &controller.RunningConfig{MetricLabels: map[string]string{"instance_name": "other"}},
+			want:        http.StatusBadRequest,
 		},
 	}
 	for _, tt := range tests {
@@ -285,6 +307,42 @@ func TestServer_start(t *testing.T) {
 	}
 }
 
+func TestServer_check(t *testing.T) {
+	tests := []struct {
+		name        string
+		resultCheck error
+		want        int
+	}{
+		{name: "ok", want: http.StatusNoContent},
+		{name: "not_exists", resultCheck: controller.ErrBlasterNotExists, want: http.StatusNotFound},
+		{name: "running", resultCheck: controller.ErrBlasterRunning, want: http.StatusPreconditionFailed},
+		{name: "conflict", resultCheck: &controller.InterfaceConflictError{Instance: "other", Interfaces: []string{"eth1"}}, want: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return configFolder
+				},
+				CheckFunc: func(name string, runningConfig controller.RunningConfig) error {
+					return tt.resultCheck
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			e.POST("/api/v1/instances/{instance_name}/_check", tt.name).
+				WithJSON(controller.RunningConfig{}).
+				Expect().
+				Status(tt.want)
+			require.Len(t, repository.CheckCalls(), 1)
+			require.Empty(t, repository.StartCalls())
+		})
+	}
+}
+
 func TestServer_stop(t *testing.T) {
 	tests := []struct {
 		name string
@@ -469,3 +527,32 @@ func TestServer_command(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_metrics(t *testing.T) {
+	repository := &controller.RepositoryMock{
+		ConfigFolderFunc: func() string {
+			return configFolder
+		},
+		ExistsFunc: func(name string) bool {
+			return false
+		},
+		InstancesFunc: func() []string {
+			return nil
+		},
+	}
+	metrics := controller.NewMetrics()
+	handler := NewServer(repository, WithMetrics(metrics))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	e := httpexpect.New(t, server.URL)
+	e.GET("/api/v1/instances/{instance_name}", "test").Expect().Status(http.StatusNotFound)
+	e.GET("/api/v1/instances/{instance_name}", "other").Expect().Status(http.StatusNotFound)
+
+	counter, err := metrics.HTTPRequests.GetMetricWithLabelValues("/api/v1/instances/{instance_name}", http.MethodGet, "404")
+	require.NoError(t, err)
+	require.Equal(t, float64(2), testutil.ToFloat64(counter))
+
+	body := e.GET("/metrics").Expect().Status(http.StatusOK).Body().Raw()
+	require.Contains(t, body, "controller_http_requests_total")
+	require.Contains(t, body, "go_goroutines")
+}
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+const (
+	sessionIDParameter = "session_id"
+
+	defaultPageLimit = 100
+	maxPageLimit     = 10000
+)
+
+// sessionFilters maps the query parameters of the session list to the
+// corresponding keys in the session objects of the bngblaster.
+var sessionFilters = map[string]string{
+	"state":      "session-state",
+	"type":       "type",
+	"interface":  "interface",
+	"outer_vlan": "outer-vlan",
+	"inner_vlan": "inner-vlan",
+	"username":   "username",
+}
+
+// SessionList is a page of sessions returned by the sessions endpoint.
+type SessionList struct {
+	Total    int                      `json:"total"`
+	Offset   int                      `json:"offset"`
+	Limit    int                      `json:"limit"`
+	Sessions []map[string]interface{} `json:"sessions"`
+}
+
+// pagination parses the offset and limit query parameters.
+func pagination(r *http.Request) (offset int, limit int, err error) {
+	limit = defaultPageLimit
+	if v := r.URL.Query().Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid offset %q", v)
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxPageLimit {
+			return 0, 0, fmt.Errorf("invalid limit %q", v)
+		}
+	}
+	return offset, limit, nil
+}
+
+// matchFilters returns true if the object matches all filters given as query parameters.
+func matchFilters(object map[string]interface{}, filters map[string]string, r *http.Request) bool {
+	query := r.URL.Query()
+	for parameter, key := range filters {
+		want := query.Get(parameter)
+		if want == "" {
+			continue
+		}
+		value, ok := object[key]
+		if !ok || fmt.Sprint(value) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// pathSessionID parses the session id from the path.
+func pathSessionID(r *http.Request) (int, error) {
+	v := mux.Vars(r)[sessionIDParameter]
+	id, err := strconv.Atoi(v)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid session id %q", v)
+	}
+	return id, nil
+}
+
+func (s *Server) sessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		offset, limit, err := pagination(r)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var cr controller.SessionsResponse
+		if !s.socketCommand(w, instance, controller.SocketCommand{Command: "sessions"}, &cr) {
+			return
+		}
+
+		list := SessionList{
+			Offset:   offset,
+			Limit:    limit,
+			Sessions: []map[string]interface{}{},
+		}
+		for _, session := range cr.Sessions {
+			if !matchFilters(session, sessionFilters, r) {
+				continue
+			}
+			if list.Total >= offset && len(list.Sessions) < limit {
+				list.Sessions = append(list.Sessions, session)
+			}
+			list.Total++
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(list)
+	}
+}
+
+func (s *Server) session() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		id, err := pathSessionID(r)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		command := controller.SocketCommand{
+			Command:   "session-info",
+			Arguments: map[string]interface{}{"session-id": id},
+		}
+		var cr controller.SessionInfoResponse
+		if !s.socketCommand(w, instance, command, &cr) {
+			return
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(cr.SessionInfo)
+	}
+}
+
+// sessionAction returns a handler that sends the given socket command for one session.
+func (s *Server) sessionAction(command string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
+		id, err := pathSessionID(r)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		sc := controller.SocketCommand{
+			Command:   command,
+			Arguments: map[string]interface{}{"session-id": id},
+		}
+		if !s.socketCommand(w, instance, sc, nil) {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
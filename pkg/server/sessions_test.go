@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+const sessionsResult = `{"status": "ok", "code": 200, "sessions": [
+	{"session-id": 1, "type": "pppoe", "session-state": "Established", "outer-vlan": 1, "inner-vlan": 1},
+	{"session-id": 2, "type": "pppoe", "session-state": "Terminated", "outer-vlan": 1, "inner-vlan": 2},
+	{"session-id": 3, "type": "ipoe", "session-state": "Established", "outer-vlan": 2, "inner-vlan": 1}
+]}`
+
+func TestServer_sessions(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		result      string
+		resultError error
+		wantTotal   int
+		wantIDs     []interface{}
+		want        int
+	}{
+		{
+			name:      "all",
+			result:    sessionsResult,
+			wantTotal: 3,
+			wantIDs:   []interface{}{1, 2, 3},
+			want:      http.StatusOK,
+		}, {
+			name:      "filter_state",
+			query:     "state=Established",
+			result:    sessionsResult,
+			wantTotal: 2,
+			wantIDs:   []interface{}{1, 3},
+			want:      http.StatusOK,
+		}, {
+			name:      "filter_vlan",
+			query:     "outer_vlan=1&inner_vlan=2",
+			result:    sessionsResult,
+			wantTotal: 1,
+			wantIDs:   []interface{}{2},
+			want:      http.StatusOK,
+		}, {
+			name:      "pagination",
+			query:     "offset=1&limit=1",
+			result:    sessionsResult,
+			wantTotal: 3,
+			wantIDs:   []interface{}{2},
+			want:      http.StatusOK,
+		}, {
+			name:  "bad_limit",
+			query: "limit=0",
+			want:  http.StatusBadRequest,
+		}, {
+			name:        "not_running",
+			resultError: controller.ErrBlasterNotRunning,
+			want:        http.StatusPreconditionFailed,
+		}, {
+			name:   "bngblaster_error",
+			result: `{"status": "error", "code": 500, "message": "internal error"}`,
+			want:   http.StatusBadGateway,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return configFolder
+				},
+				CommandFunc: func(name string, command controller.SocketCommand) ([]byte, error) {
+					return []byte(tt.result), tt.resultError
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/instances/{instance_name}/sessions", tt.name).
+				WithQueryString(tt.query).
+				Expect().
+				Status(tt.want)
+			if tt.want != http.StatusOK {
+				response.JSON().Object().ContainsKey("message")
+				return
+			}
+			object := response.JSON().Object()
+			object.ValueEqual("total", tt.wantTotal)
+			sessions := object.Value("sessions").Array()
+			sessions.Length().Equal(len(tt.wantIDs))
+			for i, id := range tt.wantIDs {
+				sessions.Element(i).Object().ValueEqual("session-id", id)
+			}
+			for _, call := range repository.CommandCalls() {
+				require.Equal(t, "sessions", call.Command.Command)
+			}
+		})
+	}
+}
+
+func TestServer_session(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		result string
+		want   int
+	}{
+		{
+			name:   "ok",
+			id:     "1",
+			result: `{"status": "ok", "code": 200, "session-info": {"session-id": 1, "session-state": "Established"}}`,
+			want:   http.StatusOK,
+		}, {
+			name:   "not_found",
+			id:     "2",
+			result: `{"status": "warning", "code": 404, "message": "session not found"}`,
+			want:   http.StatusNotFound,
+		}, {
+			name: "bad_id",
+			id:   "abc",
+			want: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return configFolder
+				},
+				CommandFunc: func(name string, command controller.SocketCommand) ([]byte, error) {
+					return []byte(tt.result), nil
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/instances/{instance_name}/sessions/{session_id}", tt.name, tt.id).
+				Expect().
+				Status(tt.want)
+			if tt.want == http.StatusOK {
+				response.JSON().Object().ValueEqual("session-state", "Established")
+			} else {
+				response.JSON().Object().ContainsKey("message")
+			}
+			for _, call := range repository.CommandCalls() {
+				require.Equal(t, "session-info", call.Command.Command)
+				require.Equal(t, tt.id, fmt.Sprint(call.Command.Arguments["session-id"]))
+			}
+		})
+	}
+}
+
+func TestServer_sessionAction(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      string
+		wantCommand string
+		result      string
+		want        int
+	}{
+		{
+			name:        "terminate",
+			action:      "_terminate",
+			wantCommand: "session-terminate",
+			result:      `{"status": "ok", "code": 200}`,
+			want:        http.StatusNoContent,
+		}, {
+			name:        "restart",
+			action:      "_restart",
+			wantCommand: "session-restart",
+			result:      `{"status": "ok", "code": 200}`,
+			want:        http.StatusNoContent,
+		}, {
+			name:        "not_found",
+			action:      "_terminate",
+			wantCommand: "session-terminate",
+			result:      `{"status": "warning", "code": 404, "message": "session not found"}`,
+			want:        http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return configFolder
+				},
+				CommandFunc: func(name string, command controller.SocketCommand) ([]byte, error) {
+					return []byte(tt.result), nil
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			e.POST("/api/v1/instances/{instance_name}/sessions/{session_id}/"+tt.action, tt.name, 7).
+				Expect().
+				Status(tt.want)
+			require.Len(t, repository.CommandCalls(), 1)
+			call := repository.CommandCalls()[0]
+			require.Equal(t, tt.name, call.Name)
+			require.Equal(t, tt.wantCommand, call.Command.Command)
+			require.EqualValues(t, 7, call.Command.Arguments["session-id"])
+		})
+	}
+}
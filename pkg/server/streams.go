@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+const flowIDParameter = "flow_id"
+
+// StreamList is a page of streams returned by the streams endpoint.
+type StreamList struct {
+	Total   int         `json:"total"`
+	Offset  int         `json:"offset"`
+	Limit   int         `json:"limit"`
+	Streams interface{} `json:"streams"`
+}
+
+// StreamSelector selects the streams of a bulk stream action.
+// All streams are selected if no field is set, otherwise the
+// streams matching all fields are selected.
+type StreamSelector struct {
+	FlowIDs   []int  `json:"flow_ids"`
+	Name      string `json:"name"`
+	Direction string `json:"direction"`
+	SessionID int    `json:"session_id"`
+}
+
+// StreamActionFailure describes a stream for which the bulk action failed.
+type StreamActionFailure struct {
+	FlowID  int    `json:"flow_id"`
+	Message string `json:"message"`
+}
+
+// StreamActionResult is the result of a bulk stream action.
+type StreamActionResult struct {
+	FlowIDs []int                 `json:"flow_ids"`
+	Failed  []StreamActionFailure `json:"failed,omitempty"`
+}
+
+// empty returns true if no stream selection is specified.
+func (ss *StreamSelector) empty() bool {
+	return len(ss.FlowIDs) == 0 && !ss.filtered()
+}
+
+// filtered returns true if a name, direction or session filter is specified.
+func (ss *StreamSelector) filtered() bool {
+	return ss.Name != "" || ss.Direction != "" || ss.SessionID != 0
+}
+
+// match returns true if the stream matches the name, direction and session filters.
+func (ss *StreamSelector) match(name string, direction string, sessionID int) bool {
+	if ss.Name != "" && ss.Name != name {
+		return false
+	}
+	if ss.Direction != "" && ss.Direction != direction {
+		return false
+	}
+	if ss.SessionID != 0 && ss.SessionID != sessionID {
+		return false
+	}
+	return true
+}
+
+// streamQuerySelector parses the stream filters from the query parameters.
+func streamQuerySelector(r *http.Request) (StreamSelector, error) {
+	query := r.URL.Query()
+	selector := StreamSelector{
+		Name:      query.Get("name"),
+		Direction: query.Get("direction"),
+	}
+	if v := query.Get("session_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			return selector, fmt.Errorf("invalid session id %q", v)
+		}
+		selector.SessionID = id
+	}
+	return selector, nil
+}
+
+func (s *Server) streams() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		offset, limit, err := pagination(r)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		selector, err := streamQuerySelector(r)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var cr controller.StreamSummaryResponse
+		if !s.socketCommand(w, instance, controller.SocketCommand{Command: "stream-summary"}, &cr) {
+			return
+		}
+
+		page := cr.Streams[:0:0]
+		list := StreamList{
+			Offset: offset,
+			Limit:  limit,
+		}
+		for _, stream := range cr.Streams {
+			if !selector.match(stream.Name, stream.Direction, stream.SessionId) {
+				continue
+			}
+			if list.Total >= offset && len(page) < limit {
+				page = append(page, stream)
+			}
+			list.Total++
+		}
+		list.Streams = page
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(list)
+	}
+}
+
+func (s *Server) stream() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		flowID, err := strconv.Atoi(mux.Vars(r)[flowIDParameter])
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		command := controller.SocketCommand{
+			Command:   "stream-info",
+			Arguments: map[string]interface{}{"flow-id": flowID},
+		}
+		var cr controller.StreamInfoResponse
+		if !s.socketCommand(w, instance, command, &cr) {
+			return
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(cr.StreamInfo)
+	}
+}
+
+// streamAction returns a handler that sends the given socket command to the
+// selected streams. The command is sent once without arguments if no
+// streams are selected, which applies the command to all streams.
+func (s *Server) streamAction(command string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		if !s.checkLease(w, r, instance, nil) {
+			return
+		}
+		var selector StreamSelector
+		if err := json.NewDecoder(r.Body).Decode(&selector); err != nil && err != io.EOF {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if selector.empty() {
+			if !s.socketCommand(w, instance, controller.SocketCommand{Command: command}, nil) {
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		flowIDs := selector.FlowIDs
+		if selector.filtered() {
+			var cr controller.StreamSummaryResponse
+			if !s.socketCommand(w, instance, controller.SocketCommand{Command: "stream-summary"}, &cr) {
+				return
+			}
+			flowIDs = nil
+			for _, stream := range cr.Streams {
+				if len(selector.FlowIDs) > 0 && !slices.Contains(selector.FlowIDs, stream.FlowId) {
+					continue
+				}
+				if selector.match(stream.Name, stream.Direction, stream.SessionId) {
+					flowIDs = append(flowIDs, stream.FlowId)
+				}
+			}
+		}
+
+		result := StreamActionResult{FlowIDs: []int{}}
+		for _, flowID := range flowIDs {
+			sc := controller.SocketCommand{
+				Command:   command,
+				Arguments: map[string]interface{}{"flow-id": flowID},
+			}
+			status, err := s.invokeCommand(instance, sc, nil)
+			var ce *commandError
+			if errors.As(err, &ce) {
+				result.Failed = append(result.Failed, StreamActionFailure{FlowID: flowID, Message: ce.Error()})
+				continue
+			}
+			if err != nil {
+				JSONError(w, err.Error(), status)
+				return
+			}
+			result.FlowIDs = append(result.FlowIDs, flowID)
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
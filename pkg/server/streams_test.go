@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+const streamSummaryResult = `{"status": "ok", "code": 200, "stream-summary": [
+	{"flow-id": 1, "name": "S1", "direction": "upstream", "session-id": 1},
+	{"flow-id": 2, "name": "S1", "direction": "downstream", "session-id": 1},
+	{"flow-id": 3, "name": "S2", "direction": "upstream", "session-id": 2}
+]}`
+
+func TestServer_streams(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantTotal int
+		wantIDs   []interface{}
+		want      int
+	}{
+		{
+			name:      "all",
+			wantTotal: 3,
+			wantIDs:   []interface{}{1, 2, 3},
+			want:      http.StatusOK,
+		}, {
+			name:      "filter_name_direction",
+			query:     "name=S1&direction=downstream",
+			wantTotal: 1,
+			wantIDs:   []interface{}{2},
+			want:      http.StatusOK,
+		}, {
+			name:      "filter_session",
+			query:     "session_id=2",
+			wantTotal: 1,
+			wantIDs:   []interface{}{3},
+			want:      http.StatusOK,
+		}, {
+			name:  "bad_session",
+			query: "session_id=x",
+			want:  http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return configFolder
+				},
+				CommandFunc: func(name string, command controller.SocketCommand) ([]byte, error) {
+					return []byte(streamSummaryResult), nil
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/instances/{instance_name}/streams", tt.name).
+				WithQueryString(tt.query).
+				Expect().
+				Status(tt.want)
+			if tt.want != http.StatusOK {
+				return
+			}
+			object := response.JSON().Object()
+			object.ValueEqual("total", tt.wantTotal)
+			streams := object.Value("streams").Array()
+			streams.Length().Equal(len(tt.wantIDs))
+			for i, id := range tt.wantIDs {
+				streams.Element(i).Object().ValueEqual("flow-id", id)
+			}
+		})
+	}
+}
+
+func TestServer_streamAction(t *testing.T) {
+	tests := []struct {
+		name         string
+		action       string
+		body         interface{}
+		wantCommands []controller.SocketCommand
+		wantFlowIDs  []int
+		wantFailed   int
+		want         int
+	}{
+		{
+			name:   "start_all",
+			action: "_start",
+			wantCommands: []controller.SocketCommand{
+				{Command: "stream-start"},
+			},
+			want: http.StatusNoContent,
+		}, {
+			name:   "stop_flow_ids",
+			action: "_stop",
+			body:   &StreamSelector{FlowIDs: []int{1, 404}},
+			wantCommands: []controller.SocketCommand{
+				{Command: "stream-stop", Arguments: map[string]interface{}{"flow-id": 1}},
+				{Command: "stream-stop", Arguments: map[string]interface{}{"flow-id": 404}},
+			},
+			wantFlowIDs: []int{1},
+			wantFailed:  1,
+			want:        http.StatusOK,
+		}, {
+			name:   "reset_name",
+			action: "_reset",
+			body:   &StreamSelector{Name: "S1"},
+			wantCommands: []controller.SocketCommand{
+				{Command: "stream-summary"},
+				{Command: "stream-reset", Arguments: map[string]interface{}{"flow-id": 1}},
+				{Command: "stream-reset", Arguments: map[string]interface{}{"flow-id": 2}},
+			},
+			wantFlowIDs: []int{1, 2},
+			want:        http.StatusOK,
+		}, {
+			name:   "stop_flow_ids_direction",
+			action: "_stop",
+			body:   &StreamSelector{FlowIDs: []int{2, 3}, Direction: "upstream"},
+			wantCommands: []controller.SocketCommand{
+				{Command: "stream-summary"},
+				{Command: "stream-stop", Arguments: map[string]interface{}{"flow-id": 3}},
+			},
+			wantFlowIDs: []int{3},
+			want:        http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return configFolder
+				},
+				CommandFunc: func(name string, command controller.SocketCommand) ([]byte, error) {
+					if command.Command == "stream-summary" {
+						return []byte(streamSummaryResult), nil
+					}
+					if command.Arguments["flow-id"] == 404 {
+						return []byte(`{"status": "warning", "code": 404, "message": "stream not found"}`), nil
+					}
+					return []byte(`{"status": "ok", "code": 200}`), nil
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			request := e.POST("/api/v1/instances/{instance_name}/streams/"+tt.action, tt.name)
+			if tt.body != nil {
+				request.WithJSON(tt.body)
+			}
+			response := request.Expect().Status(tt.want)
+			if tt.want == http.StatusOK {
+				object := response.JSON().Object()
+				object.Value("flow_ids").Equal(tt.wantFlowIDs)
+				if tt.wantFailed > 0 {
+					object.Value("failed").Array().Length().Equal(tt.wantFailed)
+				}
+			}
+			var got []controller.SocketCommand
+			for _, call := range repository.CommandCalls() {
+				got = append(got, call.Command)
+			}
+			require.Equal(t, tt.wantCommands, got)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/gorilla/mux"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+// timeseries returns the recorded counters of an instance. The metric query
+// parameter selects the metrics and supports shell patterns, e.g.
+// metric=session-counters.setup-rate&metric=interfaces.*.rx-pps.
+func (s *Server) timeseries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instanceVariable := mux.Vars(r)[instanceNameParameter]
+		instance := cleanPathVariable(instanceVariable)
+		if !s.repository.Exists(instance) {
+			JSONNotFound(w, r)
+			return
+		}
+
+		file := filepath.Join(s.repository.ConfigFolder(), instance, controller.RunTimeseriesFilename)
+		series, err := controller.ReadTimeseries(file, r.URL.Query()["metric"])
+		if os.IsNotExist(err) {
+			JSONError(w, "no timeseries recorded", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, path.ErrBadPattern) {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(contentType, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(series)
+	}
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// Copyright (C) 2020-2025, RtBrick, Inc.
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rtbrick/bngblaster-controller/pkg/controller"
+)
+
+func TestServer_timeseries(t *testing.T) {
+	folder := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "test"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "empty"), 0o755))
+	// The timeseries file is not readable.
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "unreadable", controller.RunTimeseriesFilename), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "test", controller.RunTimeseriesFilename), []byte(
+		`{"timestamp": "2025-01-01T00:00:00Z", "values": {"session-counters.setup-rate": 10, "interfaces.eth1.rx-pps": 1}}
+{"timestamp": "2025-01-01T00:00:01Z", "values": {"session-counters.setup-rate": 20, "interfaces.eth1.rx-pps": 2}}
+`), 0o644))
+
+	tests := []struct {
+		name     string
+		instance string
+		query    string
+		want     int
+		wantLen  int
+	}{
+		{name: "all", instance: "test", want: http.StatusOK, wantLen: 2},
+		{name: "metric", instance: "test", query: "metric=session-counters.setup-rate", want: http.StatusOK, wantLen: 1},
+		{name: "unknown_metric", instance: "test", query: "metric=unknown", want: http.StatusOK, wantLen: 0},
+		{name: "invalid_pattern", instance: "test", query: "metric=[", want: http.StatusBadRequest},
+		{name: "not_recorded", instance: "empty", want: http.StatusNotFound},
+		{name: "unreadable", instance: "unreadable", want: http.StatusInternalServerError},
+		{name: "not_exists", instance: "missing", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &controller.RepositoryMock{
+				ConfigFolderFunc: func() string {
+					return folder
+				},
+				ExistsFunc: func(name string) bool {
+					_, err := os.Stat(filepath.Join(folder, name))
+					return err == nil
+				},
+			}
+
+			handler := NewServer(repository)
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			e := httpexpect.New(t, server.URL)
+			response := e.GET("/api/v1/instances/{instance_name}/timeseries", tt.instance).
+				WithQueryString(tt.query).
+				Expect().
+				Status(tt.want)
+			if tt.want != http.StatusOK {
+				return
+			}
+			series := response.JSON().Array()
+			series.Length().Equal(tt.wantLen)
+			if tt.wantLen > 0 {
+				series.Element(0).Object().Value("points").Array().Length().Equal(2)
+			}
+		})
+	}
+}